@@ -0,0 +1,70 @@
+package normurl
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidData is returned for a data URI that cannot be parsed or decoded.
+var ErrInvalidData = errors.New("invalid data uri")
+
+func newData(u *url.URL) (*Locator, error) {
+	if !strings.Contains(u.Opaque, ",") {
+		return nil, fmt.Errorf("%w: missing comma", ErrInvalidData)
+	}
+	return &Locator{url: u, kind: KindData}, nil
+}
+
+// dataParts splits a data URI into its media type, payload, and whether the
+// payload is base64 encoded.
+func (l *Locator) dataParts() (string, string, bool) {
+	header, payload, _ := strings.Cut(l.url.Opaque, ",")
+	if l.url.RawQuery != "" || l.url.ForceQuery {
+		payload += "?" + l.url.RawQuery
+	}
+
+	header, encoded := strings.CutSuffix(header, ";base64")
+	return header, payload, encoded
+}
+
+// MediaType returns the media type of a data URI. A data URI without a type
+// has the default "text/plain;charset=US-ASCII" type. For other locators, an
+// empty string is returned.
+func (l *Locator) MediaType() string {
+	if l.kind != KindData {
+		return ""
+	}
+	mediaType, _, _ := l.dataParts()
+	if mediaType == "" {
+		return "text/plain;charset=US-ASCII"
+	}
+	if strings.HasPrefix(mediaType, ";") {
+		return "text/plain" + mediaType
+	}
+	return mediaType
+}
+
+// Data decodes the payload of a data URI.
+func (l *Locator) Data() ([]byte, error) {
+	if l.kind != KindData {
+		return nil, fmt.Errorf("expected data uri")
+	}
+
+	_, payload, encoded := l.dataParts()
+	decoded, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidData, err)
+	}
+	if !encoded {
+		return []byte(decoded), nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidData, err)
+	}
+	return data, nil
+}
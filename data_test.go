@@ -0,0 +1,190 @@
+package normurl_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tschaub/normurl"
+)
+
+func TestData(t *testing.T) {
+	cases := []struct {
+		input     string
+		mediaType string
+		data      string
+	}{
+		{
+			input:     "data:application/json;base64,eyJhIjogMX0=",
+			mediaType: "application/json",
+			data:      `{"a": 1}`,
+		},
+		{
+			input:     "data:text/plain;charset=utf-8,hello%20world",
+			mediaType: "text/plain;charset=utf-8",
+			data:      "hello world",
+		},
+		{
+			input:     "data:,a%2Cb?c",
+			mediaType: "text/plain;charset=US-ASCII",
+			data:      "a,b?c",
+		},
+		{
+			input:     "data:;charset=utf-8,caf%C3%A9",
+			mediaType: "text/plain;charset=utf-8",
+			data:      "café",
+		},
+		{
+			input:     "DATA:text/plain;base64,SGVsbG8=",
+			mediaType: "text/plain",
+			data:      "Hello",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input, normurl.WithAllowedSchemes("data"))
+			require.NoError(t, err)
+
+			assert.Equal(t, normurl.KindData, l.Kind())
+			assert.Equal(t, "data", l.Kind().String())
+			assert.True(t, l.IsURL())
+			assert.False(t, l.IsFilepath())
+			assert.Equal(t, c.mediaType, l.MediaType())
+
+			data, err := l.Data()
+			require.NoError(t, err)
+			assert.Equal(t, c.data, string(data))
+		})
+	}
+}
+
+func TestDataNotAllowed(t *testing.T) {
+	_, err := normurl.New("data:,hello")
+	assert.ErrorIs(t, err, normurl.ErrUnsupportedScheme)
+}
+
+func TestDataInvalid(t *testing.T) {
+	_, err := normurl.New("data:text/plain", normurl.WithAllowedSchemes("data"))
+	assert.ErrorIs(t, err, normurl.ErrInvalidData)
+
+	l, err := normurl.New("data:;base64,not*base64", normurl.WithAllowedSchemes("data"))
+	require.NoError(t, err)
+
+	_, err = l.Data()
+	assert.ErrorIs(t, err, normurl.ErrInvalidData)
+}
+
+func TestDataOtherKinds(t *testing.T) {
+	l, err := normurl.New("https://example.com/data")
+	require.NoError(t, err)
+
+	assert.Equal(t, "", l.MediaType())
+	_, err = l.Data()
+	assert.EqualError(t, err, "expected data uri")
+}
+
+func TestResolveData(t *testing.T) {
+	base, err := normurl.New("data:,hello", normurl.WithAllowedSchemes("data"))
+	require.NoError(t, err)
+
+	for _, ref := range []string{"other.txt", "/other.txt", "?query", "#fragment"} {
+		t.Run(ref, func(t *testing.T) {
+			resolved, err := base.Resolve(ref)
+			require.NoError(t, err)
+			assert.Equal(t, "data:,hello", resolved.String())
+			assert.Equal(t, normurl.KindData, resolved.Kind())
+		})
+	}
+}
+
+func TestDataEqual(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{
+			a:        "data:text/plain,hello",
+			b:        "data:text/plain,hello",
+			expected: true,
+		},
+		{
+			a:        "data:text/plain,hello",
+			b:        "data:text/plain,world",
+			expected: false,
+		},
+		{
+			a:        "data:text/plain,hello",
+			b:        "data:text/html,hello",
+			expected: false,
+		},
+		{
+			a:        "data:,a?b=1&a=2",
+			b:        "data:,a?a=2&b=1",
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.a+" "+c.b, func(t *testing.T) {
+			a, err := normurl.New(c.a, normurl.WithAllowedSchemes("data"))
+			require.NoError(t, err)
+			b, err := normurl.New(c.b, normurl.WithAllowedSchemes("data"))
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expected, a.Equal(b))
+			assert.Equal(t, c.expected, a.EqualIgnoringQuery(b))
+		})
+	}
+}
+
+func TestDataUnchanged(t *testing.T) {
+	l, err := normurl.New("data:text/plain,hello?x", normurl.WithAllowedSchemes("data"))
+	require.NoError(t, err)
+
+	l.SetQueryParam("x", "1")
+	l.AddQueryParam("y", "2")
+	l.SetQueryValues("z", []string{"3"})
+	l.ClearQuery()
+	l.SetUserinfo("user", "pass")
+	l.ClearUserinfo()
+	assert.EqualError(t, l.SetQueryParamErr("x", "1"), `cannot set query param "x" on a data uri`)
+
+	assert.Equal(t, "data:text/plain,hello?x", l.String())
+	data, err := l.Data()
+	require.NoError(t, err)
+	assert.Equal(t, "hello?x", string(data))
+
+	assert.False(t, l.HasQuery())
+	assert.Empty(t, l.Query())
+	assert.Empty(t, l.QueryParamNames())
+	_, ok := l.GetQueryParam("x")
+	assert.False(t, ok)
+	_, _, ok = l.Userinfo()
+	assert.False(t, ok)
+
+	_, err = l.SchemeRelativeString()
+	assert.EqualError(t, err, "expected URL")
+}
+
+func TestDataPathMethods(t *testing.T) {
+	l, err := normurl.New("data:text/plain,hello", normurl.WithAllowedSchemes("data"))
+	require.NoError(t, err)
+	other, err := normurl.New("data:text/plain,world", normurl.WithAllowedSchemes("data"))
+	require.NoError(t, err)
+
+	dir, err := l.Dir()
+	assert.Nil(t, dir)
+	assert.EqualError(t, err, "cannot get the directory of a data uri")
+
+	joined, err := l.JoinPath("a")
+	assert.Nil(t, joined)
+	assert.EqualError(t, err, "cannot join a path to a data uri")
+
+	_, err = l.Rel(other)
+	assert.EqualError(t, err, "cannot relate data uris")
+
+	assert.False(t, l.IsAncestor(other))
+	assert.False(t, l.IsAncestor(l))
+}
@@ -0,0 +1,154 @@
+package normurl
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+type matchPattern struct {
+	file     bool
+	scheme   string
+	host     []string
+	port     string
+	segments []string
+}
+
+func parseMatchPattern(s string) (*matchPattern, error) {
+	if i := strings.IndexAny(s, "?#"); i >= 0 {
+		s = s[:i]
+	}
+
+	p := &matchPattern{}
+	if strings.HasPrefix(s, "/") {
+		p.file = true
+		p.segments = splitSegments(s)
+		return p, validateMatchPattern(p)
+	}
+
+	i := strings.Index(s, "://")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid pattern %q", s)
+	}
+	p.scheme = strings.ToLower(s[:i])
+	rest := s[i+3:]
+
+	pathPart := ""
+	if j := strings.Index(rest, "/"); j >= 0 {
+		rest, pathPart = rest[:j], rest[j:]
+	}
+	p.segments = splitSegments(pathPart)
+
+	if p.scheme == "file" {
+		p.file = true
+		return p, validateMatchPattern(p)
+	}
+
+	host := rest
+	if j := strings.LastIndex(rest, ":"); j > strings.LastIndex(rest, "]") {
+		host, p.port = rest[:j], rest[j+1:]
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "" {
+		return nil, fmt.Errorf("invalid pattern %q", s)
+	}
+	p.host = strings.Split(strings.ToLower(host), ".")
+
+	return p, validateMatchPattern(p)
+}
+
+func validateMatchPattern(p *matchPattern) error {
+	parts := append([]string{p.scheme, p.port}, p.host...)
+	parts = append(parts, p.segments...)
+	for _, part := range parts {
+		if _, err := path.Match(part, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", part, err)
+		}
+	}
+	return nil
+}
+
+// splitSegments splits an escaped path into unescaped segments (escaped
+// slashes within a segment are kept escaped so they cannot match a separator).
+func splitSegments(escaped string) []string {
+	escaped = strings.TrimPrefix(escaped, "/")
+	if escaped == "" {
+		return nil
+	}
+	segments := strings.Split(escaped, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segments[i] = strings.ReplaceAll(unescaped, "/", "%2F")
+		}
+	}
+	return segments
+}
+
+func matchParts(patterns []string, parts []string) bool {
+	if len(patterns) != len(parts) {
+		return false
+	}
+	for i, pattern := range patterns {
+		if ok, _ := path.Match(pattern, parts[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func matchSegments(patterns []string, segments []string) bool {
+	if len(patterns) == 0 {
+		return len(segments) == 0
+	}
+	if patterns[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(patterns[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(patterns[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(patterns[1:], segments[1:])
+}
+
+// Matches checks if a locator matches a glob-style pattern like
+// "https://*.example.com/api/**". In the host, "*" matches a single label. In
+// the path, "*" matches within a single segment and "**" matches any number of
+// segments. The scheme and port may also be "*". If the pattern has no port,
+// any port matches. The query and fragment are ignored for both the pattern
+// and the locator. Patterns that are absolute paths or file:// URLs match file
+// paths.
+func (l *Locator) Matches(pattern string) (bool, error) {
+	p, err := parseMatchPattern(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	if p.file != (l.kind == KindFile) {
+		return false, nil
+	}
+
+	if l.kind == KindFile {
+		return matchSegments(p.segments, splitSegments(l.style.toSlash(l.url.Path))), nil
+	}
+
+	if ok, _ := path.Match(p.scheme, l.url.Scheme); !ok {
+		return false, nil
+	}
+	if !matchParts(p.host, strings.Split(l.url.Hostname(), ".")) {
+		return false, nil
+	}
+	if p.port != "" {
+		if ok, _ := path.Match(p.port, l.url.Port()); !ok {
+			return false, nil
+		}
+	}
+	return matchSegments(p.segments, splitSegments(l.url.EscapedPath())), nil
+}
@@ -0,0 +1,159 @@
+package normurl_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tschaub/normurl"
+)
+
+func TestMatches(t *testing.T) {
+	cases := []struct {
+		input    string
+		pattern  string
+		expected bool
+	}{
+		{
+			input:    "https://api.example.com/api/users",
+			pattern:  "https://*.example.com/api/*",
+			expected: true,
+		},
+		{
+			input:    "https://example.com/api/users",
+			pattern:  "https://*.example.com/api/*",
+			expected: false,
+		},
+		{
+			input:    "https://a.b.example.com/api/users",
+			pattern:  "https://*.example.com/api/*",
+			expected: false,
+		},
+		{
+			input:    "https://a.b.example.com/api/users",
+			pattern:  "https://*.*.example.com/api/*",
+			expected: true,
+		},
+		{
+			input:    "https://api.example.com/api/users/123",
+			pattern:  "https://*.example.com/api/*",
+			expected: false,
+		},
+		{
+			input:    "https://api.example.com/api/users/123",
+			pattern:  "https://*.example.com/api/**",
+			expected: true,
+		},
+		{
+			input:    "https://api.example.com/api",
+			pattern:  "https://*.example.com/api/**",
+			expected: true,
+		},
+		{
+			input:    "https://api.example.com/api/v1/users/123",
+			pattern:  "https://api.example.com/api/**/123",
+			expected: true,
+		},
+		{
+			input:    "https://api.example.com/api/a%2Fb",
+			pattern:  "https://api.example.com/api/*",
+			expected: true,
+		},
+		{
+			input:    "https://example.com/schemas/foo.json",
+			pattern:  "https://example.com/schemas/*.json",
+			expected: true,
+		},
+		{
+			input:    "https://example.com/api/users?token=secret#frag",
+			pattern:  "https://example.com/api/users",
+			expected: true,
+		},
+		{
+			input:    "https://EXAMPLE.com/api/users",
+			pattern:  "https://example.COM/api/users",
+			expected: true,
+		},
+		{
+			input:    "http://example.com/api/users",
+			pattern:  "https://example.com/api/users",
+			expected: false,
+		},
+		{
+			input:    "http://example.com/api/users",
+			pattern:  "*://example.com/api/users",
+			expected: true,
+		},
+		{
+			input:    "https://example.com:8443/api",
+			pattern:  "https://example.com/api",
+			expected: true,
+		},
+		{
+			input:    "https://example.com:8443/api",
+			pattern:  "https://example.com:443/api",
+			expected: false,
+		},
+		{
+			input:    "https://example.com",
+			pattern:  "https://example.com",
+			expected: true,
+		},
+		{
+			input:    "https://example.com/",
+			pattern:  "https://example.com/**",
+			expected: true,
+		},
+		{
+			input:    "/path/to/file.json",
+			pattern:  "/path/**/*.json",
+			expected: true,
+		},
+		{
+			input:    "/path/to/file.json",
+			pattern:  "file:///path/*/file.json",
+			expected: true,
+		},
+		{
+			input:    "/path/to/file.json",
+			pattern:  "https://example.com/**",
+			expected: false,
+		},
+		{
+			input:    "https://example.com/path/to/file.json",
+			pattern:  "/path/**",
+			expected: false,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			l, err := normurl.New(c.input)
+			require.NoError(t, err)
+
+			matched, err := l.Matches(c.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, matched)
+		})
+	}
+}
+
+func TestMatchesInvalidPattern(t *testing.T) {
+	l, err := normurl.New("https://example.com/path")
+	require.NoError(t, err)
+
+	cases := []string{
+		"example.com/path",
+		"https:///path",
+		"https://example.com/[",
+	}
+
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			matched, err := l.Matches(c)
+			assert.Error(t, err)
+			assert.False(t, matched)
+		})
+	}
+}
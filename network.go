@@ -0,0 +1,102 @@
+package normurl
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrInvalidIPv6 is returned for a URL with an IPv6 host that is not enclosed
+// in brackets or is not a valid IPv6 address.
+var ErrInvalidIPv6 = errors.New("invalid IPv6 host")
+
+// checkIPv6Host checks the authority of a URL string with an IPv6 host. This
+// gives a clearer error than url.Parse, which reports an unbracketed address
+// as an invalid port. Only strings that start with a scheme followed by "://"
+// have an authority to check (so file paths and opaque URIs are skipped).
+func checkIPv6Host(s string) error {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok || !isScheme(scheme) {
+		return nil
+	}
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		rest = rest[i+1:]
+	}
+
+	if !strings.HasPrefix(rest, "[") {
+		if strings.Count(rest, ":") > 1 {
+			return fmt.Errorf("%w %s: address must be enclosed in brackets", ErrInvalidIPv6, rest)
+		}
+		return nil
+	}
+
+	end := strings.Index(rest, "]")
+	if end < 0 {
+		return fmt.Errorf("%w %s: missing closing bracket", ErrInvalidIPv6, rest)
+	}
+	host := rest[1:end]
+	if i := strings.Index(host, "%"); i >= 0 {
+		host = host[:i]
+	}
+	if !strings.Contains(host, ":") || net.ParseIP(host) == nil {
+		return fmt.Errorf("%w %s: not an IPv6 address", ErrInvalidIPv6, rest[:end+1])
+	}
+	return nil
+}
+
+// isScheme checks if a string is a valid URL scheme (a letter followed by
+// letters, digits, "+", "-", or ".").
+func isScheme(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// hostIP returns the IP address for a URL host, or nil if the host is not an IP literal.
+func (l *Locator) hostIP() net.IP {
+	if l.kind == KindFile {
+		return nil
+	}
+	host := l.url.Hostname()
+	if i := strings.LastIndex(host, "%"); i >= 0 {
+		host = host[:i]
+	}
+	return net.ParseIP(host)
+}
+
+// IsLoopback checks if a URL points at localhost (or a subdomain of it), a
+// loopback address, or an unspecified address (e.g. 0.0.0.0). File paths are
+// never loopback.
+func (l *Locator) IsLoopback() bool {
+	if l.kind == KindFile {
+		return false
+	}
+	hostname := strings.TrimSuffix(l.url.Hostname(), ".")
+	if hostname == "localhost" || strings.HasSuffix(hostname, ".localhost") {
+		return true
+	}
+	ip := l.hostIP()
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
+
+// IsPrivateNetwork checks if a URL host is an IP address in a private
+// (RFC 1918 or RFC 4193) or link-local range. Only IP literals are checked, and
+// host names are not resolved, so no network access is performed. File paths
+// are never on a private network.
+func (l *Locator) IsPrivateNetwork() bool {
+	ip := l.hostIP()
+	return ip != nil && (ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast())
+}
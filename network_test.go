@@ -0,0 +1,268 @@
+package normurl_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tschaub/normurl"
+)
+
+func TestIsLoopback(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected bool
+	}{
+		{
+			input:    "http://localhost/",
+			expected: true,
+		},
+		{
+			input:    "http://LOCALHOST:8080/",
+			expected: true,
+		},
+		{
+			input:    "http://app.localhost/",
+			expected: true,
+		},
+		{
+			input:    "http://127.0.0.1/",
+			expected: true,
+		},
+		{
+			input:    "http://127.1.2.3:8080/",
+			expected: true,
+		},
+		{
+			input:    "http://[::1]/",
+			expected: true,
+		},
+		{
+			input:    "http://[::1]:8080/",
+			expected: true,
+		},
+		{
+			input:    "http://[::ffff:127.0.0.1]/",
+			expected: true,
+		},
+		{
+			input:    "http://0.0.0.0/",
+			expected: true,
+		},
+		{
+			input:    "https://example.com/",
+			expected: false,
+		},
+		{
+			input:    "https://localhost.example.com/",
+			expected: false,
+		},
+		{
+			input:    "http://8.8.8.8/",
+			expected: false,
+		},
+		{
+			input:    "http://[2001:4860:4860::8888]/",
+			expected: false,
+		},
+		{
+			input:    "/localhost/file",
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expected, l.IsLoopback())
+		})
+	}
+}
+
+func TestIsPrivateNetwork(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected bool
+	}{
+		{
+			input:    "http://10.0.0.1/",
+			expected: true,
+		},
+		{
+			input:    "http://10.255.255.255:8080/",
+			expected: true,
+		},
+		{
+			input:    "http://172.16.0.1/",
+			expected: true,
+		},
+		{
+			input:    "http://172.31.255.255/",
+			expected: true,
+		},
+		{
+			input:    "http://172.32.0.1/",
+			expected: false,
+		},
+		{
+			input:    "http://192.168.1.1/",
+			expected: true,
+		},
+		{
+			input:    "http://169.254.169.254/",
+			expected: true,
+		},
+		{
+			input:    "http://[fc00::1]/",
+			expected: true,
+		},
+		{
+			input:    "http://[fd12:3456:789a::1]:8080/",
+			expected: true,
+		},
+		{
+			input:    "http://[fe80::1]/",
+			expected: true,
+		},
+		{
+			input:    "http://[fe80::1%25en0]/",
+			expected: true,
+		},
+		{
+			input:    "http://[::ffff:192.168.1.1]/",
+			expected: true,
+		},
+		{
+			input:    "http://8.8.8.8/",
+			expected: false,
+		},
+		{
+			input:    "http://[2001:4860:4860::8888]/",
+			expected: false,
+		},
+		{
+			input:    "http://127.0.0.1/",
+			expected: false,
+		},
+		{
+			input:    "https://internal.example.com/",
+			expected: false,
+		},
+		{
+			input:    "/10.0.0.1/file",
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expected, l.IsPrivateNetwork())
+		})
+	}
+}
+
+func TestIPv6Host(t *testing.T) {
+	cases := []struct {
+		input    string
+		hostname string
+		port     string
+		err      string
+	}{
+		{
+			input:    "https://[::1]:8080/x",
+			hostname: "::1",
+			port:     "8080",
+		},
+		{
+			input:    "https://user:pass@[2001:DB8::1]/x?a=b:c:d",
+			hostname: "2001:db8::1",
+		},
+		{
+			input:    "https://[fe80::1%25en0]/x",
+			hostname: "fe80::1%en0",
+		},
+		{
+			input:    "https://example.com:8080/a:b:c",
+			hostname: "example.com",
+			port:     "8080",
+		},
+		{
+			input: "https://2001:db8::1/x",
+			err:   "invalid IPv6 host 2001:db8::1: address must be enclosed in brackets",
+		},
+		{
+			input: "https://::1:8080/x",
+			err:   "invalid IPv6 host ::1:8080: address must be enclosed in brackets",
+		},
+		{
+			input: "https://[::1/x",
+			err:   "invalid IPv6 host [::1: missing closing bracket",
+		},
+		{
+			input: "https://[1.2.3.4]/x",
+			err:   "invalid IPv6 host [1.2.3.4]: not an IPv6 address",
+		},
+		{
+			input: "https://[example.com]:8080/x",
+			err:   "invalid IPv6 host [example.com]: not an IPv6 address",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input)
+			if c.err != "" {
+				assert.Nil(t, l)
+				assert.ErrorIs(t, err, normurl.ErrInvalidIPv6)
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.hostname, l.Hostname())
+			assert.Equal(t, c.port, l.ExplicitPort())
+		})
+	}
+}
+
+func TestIPv6HostOutsideAuthority(t *testing.T) {
+	cases := []struct {
+		input string
+		opts  []normurl.Option
+		goos  string
+		kind  normurl.Kind
+	}{
+		{
+			input: "/tmp/http://a:b:c",
+			goos:  "!windows",
+			kind:  normurl.KindFile,
+		},
+		{
+			input: "data:text/plain,see http://a:b:c",
+			opts:  []normurl.Option{normurl.WithAllowedSchemes("data")},
+			kind:  normurl.KindData,
+		},
+		{
+			input: "https:/p?x=http://a::b",
+			kind:  normurl.KindURL,
+		},
+		{
+			input: "https://example.com/p?x=http://a::b",
+			kind:  normurl.KindURL,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			skipUnlessGOOS(t, c.goos)
+
+			l, err := normurl.New(c.input, c.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, c.kind, l.Kind())
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package normurl
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// hostProfile is the IDNA lookup profile without the STD3 rules, which would
+// reject characters like "_" that are allowed in URL hosts.
+var hostProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalize creates a new locator with a normalized URL. The host is converted
+// to lowercase, the default port for the scheme is removed, and "." and ".."
+// segments are removed from the path (".." segments that would climb above
+// the root are dropped). An empty path for a URL with a host becomes "/", so
+// "https://example.com" and "https://example.com/" normalize to the same
+// locator. New does not do this, so String returns the path as given. In the
+// path and query, percent-encoded unreserved characters are decoded (e.g. "%41"
+// becomes "A") and other escapes use uppercase hex (e.g. "%2f" becomes "%2F").
+// File paths are returned unchanged.
+func (l *Locator) Normalize() *Locator {
+	n := l.Clone()
+	if n.kind == KindFile {
+		return n
+	}
+
+	n.url.Host = strings.ToLower(n.url.Host)
+	stripDefaultPort(n.url)
+	if n.url.Path == "" && n.url.Host != "" {
+		n.url.Path = "/"
+	}
+	// the escaped path is always valid, and on error the path is left unchanged
+	_ = setEscapedPath(n.url, removeDotSegments(normalizeEscapes(n.url.EscapedPath())))
+	n.url.RawQuery = normalizeEscapes(n.url.RawQuery)
+	return n
+}
+
+// CacheKey returns a canonical string for use as a cache key. For URLs, the
+// locator is normalized, query params are sorted, and the fragment is removed.
+// For file paths, the path is cleaned and the fragment is removed.
+func (l *Locator) CacheKey() string {
+	switch l.kind {
+	case KindData:
+		return l.String()
+	case KindFile:
+		c := l.Clean()
+		c.url.Fragment = ""
+		c.url.RawFragment = ""
+		return c.String()
+	}
+
+	n := l.Normalize().url
+	u := &url.URL{
+		Scheme:   strings.ToLower(n.Scheme),
+		User:     n.User,
+		Host:     n.Host,
+		Path:     n.Path,
+		RawPath:  n.RawPath,
+		RawQuery: n.Query().Encode(),
+	}
+	return u.String()
+}
+
+// ToASCII creates a new locator with an internationalized host converted to
+// its ASCII (punycode) form. Hosts that are already ASCII (including names like
+// "my_host" that are not valid IDNA labels) and file paths are returned
+// unchanged.
+func (l *Locator) ToASCII() (*Locator, error) {
+	n := l.Clone()
+	if n.kind == KindFile {
+		return n, nil
+	}
+
+	hostname := n.url.Hostname()
+	if hostname == "" || isASCII(hostname) || net.ParseIP(hostname) != nil {
+		return n, nil
+	}
+
+	ascii, err := hostProfile.ToASCII(hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	if port := n.url.Port(); port != "" {
+		n.url.Host = ascii + ":" + port
+	} else {
+		n.url.Host = ascii
+	}
+	return n, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeEscapes decodes percent-encoded unreserved characters and uppercases
+// the hex digits of the remaining escapes. Reserved characters (like "/") stay
+// encoded, since decoding them would change the meaning of the URL.
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteString(strings.ToUpper(s[i : i+3]))
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// isUnreserved checks if a character is unreserved (RFC 3986 section 2.3).
+func isUnreserved(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func stripDefaultPort(u *url.URL) {
+	port := u.Port()
+	if port == "" || port != defaultPorts[u.Scheme] {
+		return
+	}
+	host := u.Hostname()
+	if net.ParseIP(host) != nil && net.ParseIP(host).To4() == nil {
+		host = "[" + host + "]"
+	}
+	u.Host = host
+}
+
+func removeDotSegments(p string) string {
+	if !strings.Contains(p, ".") {
+		return p
+	}
+
+	abs := strings.HasPrefix(p, "/")
+	segments := strings.Split(p, "/")
+	if abs {
+		segments = segments[1:]
+	}
+
+	out := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch segment {
+		case ".":
+			if last {
+				out = append(out, "")
+			}
+		case "..":
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+			if last {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, segment)
+		}
+	}
+
+	cleaned := strings.Join(out, "/")
+	if abs {
+		cleaned = "/" + cleaned
+	}
+	return cleaned
+}
@@ -1,49 +1,131 @@
 package normurl
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
-	"path/filepath"
-	"runtime"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 )
 
+var (
+	// ErrUnsupportedScheme is returned for a URL with a scheme that is not allowed.
+	ErrUnsupportedScheme = errors.New("unsupported scheme")
+	// ErrRelativePath is returned for a file path that is not absolute.
+	ErrRelativePath = errors.New("expected absolute path")
+	// ErrMissingURL is returned when decoding a locator without a URL.
+	ErrMissingURL = errors.New("missing url")
+	// ErrFileFlagMismatch is returned when decoding a locator with a File flag
+	// that does not match the kind of locator for the URL.
+	ErrFileFlagMismatch = errors.New("file flag mismatch")
+	// ErrFileHost is returned for a file:// URL with a host other than
+	// localhost when paths are not interpreted with the Windows style (which
+	// treats the host as a UNC server).
+	ErrFileHost = errors.New("unsupported file url host")
+	// ErrInvalidCharacter is returned for a URL with a character that is not
+	// allowed when using the WithStrict option.
+	ErrInvalidCharacter = errors.New("invalid character")
+)
+
+// Kind identifies the type of resource a locator represents.
+type Kind int
+
+const (
+	// KindURL is a URL locator.
+	KindURL Kind = iota
+	// KindFile is a file path locator.
+	KindFile
+	// KindData is a data URI locator.
+	KindData
+)
+
+// String returns a readable name for the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindURL:
+		return "url"
+	case KindFile:
+		return "file"
+	case KindData:
+		return "data"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
 // Locator represents a file path or a URL.
 type Locator struct {
-	url  *url.URL
-	file bool
+	url   *url.URL
+	kind  Kind
+	raw   string
+	style PathStyle
+	opts  *options
+	// forceFragment records a bare "#", which url.URL does not keep (like
+	// url.URL.ForceQuery does for a bare "?")
+	forceFragment bool
 }
 
 type jsonLocator struct {
 	Url  string
-	File bool
+	File *bool
 }
 
 var _ json.Unmarshaler = (*Locator)(nil)
 
-// UnmarshalJSON creates a locator from JSON data
+// UnmarshalJSON creates a locator from JSON data (either a string or an object
+// with Url and File fields). Errors include the url that could not be decoded
 func (l *Locator) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '"' {
+		var s string
+		if err := json.Unmarshal(trimmed, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			return ErrMissingURL
+		}
+		if err := l.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid url %q: %w", s, err)
+		}
+		return nil
+	}
+
 	var jl jsonLocator
 	if err := json.Unmarshal(data, &jl); err != nil {
 		return err
 	}
 
+	return l.fromJSONLocator(jl)
+}
+
+func (l *Locator) fromJSONLocator(jl jsonLocator) error {
 	if jl.Url == "" {
-		return fmt.Errorf("missing url")
+		return ErrMissingURL
 	}
 
-	nl, newErr := New(jl.Url)
-	if newErr != nil {
-		return newErr
+	var nl *Locator
+	if jl.File != nil && *jl.File {
+		nl, _ = fileFromJSON(jl.Url)
+	}
+	if nl == nil {
+		var newErr error
+		nl, newErr = New(jl.Url)
+		if newErr != nil {
+			return fmt.Errorf("invalid url %q: %w", jl.Url, newErr)
+		}
 	}
 
-	if jl.File != nl.file {
-		return fmt.Errorf("file flag mismatch")
+	// the file flag is only checked if it is provided
+	if jl.File != nil && *jl.File != nl.IsFilepath() {
+		return fmt.Errorf("%w for %q (file is %t)", ErrFileFlagMismatch, jl.Url, *jl.File)
 	}
 
-	l.file = jl.File
-	l.url = nl.url
+	*l = *nl
 
 	return nil
 }
@@ -52,20 +134,328 @@ var _ json.Marshaler = (*Locator)(nil)
 
 // MarshalJSON encodes a locator as JSON
 func (l *Locator) MarshalJSON() ([]byte, error) {
-	jl := jsonLocator{
-		Url:  l.url.String(),
-		File: l.file,
+	return json.Marshal(l.toJSONLocator())
+}
+
+// fileFromJSON creates a file locator from the escaped slash path (and
+// optional fragment) written by toJSONLocator. It is not parsed as a URL so
+// that Windows drive paths are handled on any OS.
+func fileFromJSON(s string) (*Locator, error) {
+	escapedPath, escapedFragment, _ := strings.Cut(s, "#")
+	p, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		return nil, err
+	}
+	fragment, err := url.PathUnescape(escapedFragment)
+	if err != nil {
+		return nil, err
+	}
+	l, err := NewFile(p)
+	if err != nil {
+		return nil, err
+	}
+	l.url.Fragment = fragment
+	l.raw = s
+	return l, nil
+}
+
+func (l *Locator) toJSONLocator() jsonLocator {
+	file := l.IsFilepath()
+	value := l.url.String()
+	if file {
+		value = (&url.URL{Path: l.SlashPath()}).EscapedPath()
+		if l.url.Fragment != "" {
+			value += "#" + l.url.EscapedFragment()
+		}
+	}
+	return jsonLocator{
+		Url:  value,
+		File: &file,
+	}
+}
+
+// StringLocator is a locator that is encoded as a JSON string instead of an
+// object (file paths are encoded as file:// URLs). Both forms are accepted when
+// decoding.
+type StringLocator struct {
+	Locator
+}
+
+var _ json.Marshaler = StringLocator{}
+
+// MarshalJSON encodes a locator as a JSON string
+func (l StringLocator) MarshalJSON() ([]byte, error) {
+	text, err := l.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+var _ encoding.TextUnmarshaler = (*Locator)(nil)
+
+// UnmarshalText creates a locator from text
+func (l *Locator) UnmarshalText(data []byte) error {
+	nl, err := New(string(data))
+	if err != nil {
+		return err
+	}
+
+	*l = *nl
+
+	return nil
+}
+
+var _ encoding.TextMarshaler = (*Locator)(nil)
+
+// MarshalText encodes a locator as text (file paths are encoded as file:// URLs)
+func (l *Locator) MarshalText() ([]byte, error) {
+	if l.kind == KindFile {
+		fileURL, err := l.ToFileURL()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(fileURL), nil
+	}
+	return []byte(l.url.String()), nil
+}
+
+// UnmarshalYAML creates a locator from a YAML scalar
+func (l *Locator) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
 	}
-	return json.Marshal(jl)
+	return l.UnmarshalText([]byte(s))
+}
+
+// MarshalYAML encodes a locator as a YAML scalar (file paths are encoded as file:// URLs)
+func (l *Locator) MarshalYAML() (interface{}, error) {
+	text, err := l.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+var _ gob.GobDecoder = (*Locator)(nil)
+
+// GobDecode creates a locator from gob data
+func (l *Locator) GobDecode(data []byte) error {
+	var jl jsonLocator
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&jl); err != nil {
+		return err
+	}
+	return l.fromJSONLocator(jl)
+}
+
+var _ gob.GobEncoder = (*Locator)(nil)
+
+// GobEncode encodes a locator as gob data
+func (l *Locator) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(l.toJSONLocator()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 func (l *Locator) String() string {
 	return l.url.String()
 }
 
-// SetQueryParam updates the query param for a URL (pass an empty string to delete a param).
+var _ fmt.GoStringer = (*Locator)(nil)
+
+// GoString returns a readable form of the locator components for use with %#v.
+func (l *Locator) GoString() string {
+	if l == nil || l.url == nil {
+		return "(*normurl.Locator)(nil)"
+	}
+	return fmt.Sprintf(
+		"&normurl.Locator{Kind: %s, Scheme: %q, Host: %q, Path: %q, Query: %q, Fragment: %q}",
+		l.kind, l.Scheme(), l.Host(), l.Path(), l.url.RawQuery, l.url.Fragment,
+	)
+}
+
+// Redacted returns the string form with any password replaced by "xxxxx" (like
+// url.URL.Redacted). The values of the named query params are also replaced
+// by "xxxxx", leaving the order of params unchanged. Param names are matched
+// without regard to case.
+func (l *Locator) Redacted(sensitiveParams ...string) string {
+	if l.kind != KindURL {
+		return l.String()
+	}
+
+	u := *l.url
+	if u.RawQuery != "" && len(sensitiveParams) > 0 {
+		parts := strings.Split(u.RawQuery, "&")
+		for i, part := range parts {
+			key, _, _ := strings.Cut(part, "=")
+			name, err := url.QueryUnescape(key)
+			if err != nil {
+				name = key
+			}
+			for _, param := range sensitiveParams {
+				if strings.EqualFold(name, param) {
+					parts[i] = key + "=xxxxx"
+					break
+				}
+			}
+		}
+		u.RawQuery = strings.Join(parts, "&")
+	}
+	return u.Redacted()
+}
+
+// RawString returns the input used to create the locator, before any
+// normalization applied by New (e.g. lowercasing the host). The input is not
+// updated when the locator is modified. For locators that were not created
+// from an input (e.g. by Resolve), this is the same as String.
+func (l *Locator) RawString() string {
+	if l.raw == "" {
+		return l.String()
+	}
+	return l.raw
+}
+
+// SchemeRelativeString returns the scheme-relative form of a URL (e.g.
+// "//example.com/path"). File paths, data URIs, and URLs without a host (e.g.
+// "https:path" or "https:/path") return an error.
+func (l *Locator) SchemeRelativeString() (string, error) {
+	if l.kind != KindURL {
+		return "", fmt.Errorf("expected URL")
+	}
+	if l.url.Host == "" || l.url.Opaque != "" {
+		return "", fmt.Errorf("expected URL with a host")
+	}
+	u := *l.url
+	u.Scheme = ""
+	return u.String(), nil
+}
+
+// Clone creates a copy of a locator that can be modified independently.
+func (l *Locator) Clone() *Locator {
+	u := *l.url
+	return &Locator{
+		url:           &u,
+		kind:          l.kind,
+		raw:           l.raw,
+		style:         l.style,
+		opts:          l.opts,
+		forceFragment: l.forceFragment,
+	}
+}
+
+// options returns the options used to create the locator.
+func (l *Locator) options() *options {
+	if l.opts != nil {
+		return l.opts
+	}
+	return newOptions([]Option{WithPathStyle(l.style)})
+}
+
+// Equal checks if two locators represent the same resource. File paths are
+// compared after cleaning. URLs are normalized and compared with a
+// case-insensitive scheme and host, and query params are compared without
+// regard to the order of params (the order of multiple values for the same
+// param is significant). Data URIs are compared by their string form, since
+// the query is part of the data.
+func (l *Locator) Equal(other *Locator) bool {
+	if other == nil || l.kind != other.kind {
+		return false
+	}
+	switch l.kind {
+	case KindFile:
+		return l.style.clean(l.url.Path) == other.style.clean(other.url.Path) && l.url.Fragment == other.url.Fragment
+	case KindData:
+		// the query of a data URI is part of the data
+		return l.String() == other.String()
+	}
+	return l.comparisonKey() == other.comparisonKey()
+}
+
+// EqualFold checks if two locators are equal like Equal, but compares file
+// paths without regard to case (as on case-insensitive file systems such as
+// the defaults on macOS and Windows). URLs are compared like Equal.
+func (l *Locator) EqualFold(other *Locator) bool {
+	if other == nil || l.kind != KindFile || other.kind != KindFile {
+		return l.Equal(other)
+	}
+	return strings.EqualFold(l.style.clean(l.url.Path), other.style.clean(other.url.Path)) && l.url.Fragment == other.url.Fragment
+}
+
+// EqualIgnoringQuery checks if two locators are equal like Equal, but ignores
+// the query and fragment. Data URIs are compared like Equal.
+func (l *Locator) EqualIgnoringQuery(other *Locator) bool {
+	if other == nil || l.kind == KindData {
+		return l.Equal(other)
+	}
+	a := l.StripQuery().StripFragment()
+	b := other.StripQuery().StripFragment()
+	return a.Equal(b)
+}
+
+// IsAncestor checks if another locator is the same as or nested under this
+// one. Paths are cleaned before comparison, so ".." segments cannot be used to
+// escape the ancestor. URLs must also have the same scheme and host, and their
+// escaped paths are compared (so "%2F" does not separate segments). Data URIs
+// are never ancestors.
+func (l *Locator) IsAncestor(other *Locator) bool {
+	if other == nil || l.kind != other.kind || l.kind == KindData {
+		return false
+	}
+
+	if l.kind == KindFile {
+		rel, err := l.style.rel(l.style.clean(l.url.Path), l.style.clean(other.url.Path))
+		if err != nil {
+			return false
+		}
+		return rel != ".." && !strings.HasPrefix(rel, ".."+l.style.separator())
+	}
+
+	if !strings.EqualFold(l.url.Scheme, other.url.Scheme) || !strings.EqualFold(l.url.Host, other.url.Host) {
+		return false
+	}
+	// escaped paths are compared so that "%2F" does not act as a separator
+	basePath := path.Clean("/" + normalizeEscapes(l.url.EscapedPath()))
+	otherPath := path.Clean("/" + normalizeEscapes(other.url.EscapedPath()))
+	if basePath == "/" || basePath == otherPath {
+		return true
+	}
+	return strings.HasPrefix(otherPath, basePath+"/")
+}
+
+// SameOrigin checks if two URLs have the same scheme, host, and port. A URL
+// without a port has the default port for its scheme, so "https://example.com"
+// and "https://example.com:443" have the same origin. File paths and data URIs
+// never have the same origin as another locator.
+func (l *Locator) SameOrigin(other *Locator) bool {
+	if other == nil || l.kind != KindURL || other.kind != KindURL {
+		return false
+	}
+	return strings.EqualFold(l.url.Scheme, other.url.Scheme) &&
+		strings.EqualFold(l.url.Hostname(), other.url.Hostname()) &&
+		l.Port() == other.Port()
+}
+
+func (l *Locator) comparisonKey() string {
+	n := l.Normalize().url
+	u := &url.URL{
+		Scheme:   strings.ToLower(n.Scheme),
+		User:     n.User,
+		Opaque:   n.Opaque,
+		Host:     strings.ToLower(n.Host),
+		Path:     n.Path,
+		RawQuery: n.Query().Encode(),
+		Fragment: n.Fragment,
+	}
+	return u.String()
+}
+
+// SetQueryParam updates the query param for a URL (pass an empty string to
+// delete a param). File paths and data URIs are not modified.
 func (l *Locator) SetQueryParam(param string, value string) {
-	if l.file {
+	if l.kind != KindURL {
 		return
 	}
 	query := l.url.Query()
@@ -77,83 +467,1073 @@ func (l *Locator) SetQueryParam(param string, value string) {
 	l.url.RawQuery = query.Encode()
 }
 
+// WithQueryParam creates a new locator with the query param updated (pass an
+// empty string to delete a param), leaving the original unchanged.
+func (l *Locator) WithQueryParam(param string, value string) *Locator {
+	clone := l.Clone()
+	clone.SetQueryParam(param, value)
+	return clone
+}
+
+// AddQueryParam adds a value to a query param for a URL, keeping any existing
+// values (like url.Values.Add). File paths and data URIs are not modified.
+func (l *Locator) AddQueryParam(param string, value string) {
+	if l.kind != KindURL {
+		return
+	}
+	query := l.url.Query()
+	query.Add(param, value)
+	l.url.RawQuery = query.Encode()
+}
+
+// SetQueryParamErr updates the query param for a URL like SetQueryParam, but
+// returns an error instead of ignoring the call for file paths and data URIs.
+func (l *Locator) SetQueryParamErr(param string, value string) error {
+	if l.kind == KindFile {
+		return fmt.Errorf("cannot set query param %q on a file path", param)
+	}
+	if l.kind == KindData {
+		return fmt.Errorf("cannot set query param %q on a data uri", param)
+	}
+	l.SetQueryParam(param, value)
+	return nil
+}
+
+// SetQueryValues replaces all values for a query param (pass an empty slice to
+// delete a param). File paths and data URIs are not modified.
+func (l *Locator) SetQueryValues(param string, values []string) {
+	if l.kind != KindURL {
+		return
+	}
+	query := l.url.Query()
+	if len(values) > 0 {
+		query[param] = append([]string(nil), values...)
+	} else {
+		query.Del(param)
+	}
+	l.url.RawQuery = query.Encode()
+}
+
+// ClearQuery removes all query params from a URL.
+func (l *Locator) ClearQuery() {
+	if l.kind != KindURL {
+		return
+	}
+	l.url.RawQuery = ""
+	l.url.ForceQuery = false
+}
+
+// StripQuery creates a new locator with the query removed.
+func (l *Locator) StripQuery() *Locator {
+	clone := l.Clone()
+	clone.ClearQuery()
+	return clone
+}
+
+// SortQuery creates a new locator with the query params encoded in sorted
+// order by key and, for a repeated key, by value.
+func (l *Locator) SortQuery() *Locator {
+	clone := l.Clone()
+	if l.kind != KindURL || clone.url.RawQuery == "" {
+		return clone
+	}
+	query := clone.url.Query()
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	clone.url.RawQuery = query.Encode()
+	return clone
+}
+
+// OrderedQueryParams returns the query params for a URL as key/value pairs in
+// the order they appear in the query (an empty slice for file paths). As with
+// Query, params that cannot be decoded are skipped.
+func (l *Locator) OrderedQueryParams() [][2]string {
+	params := [][2]string{}
+	if l.kind != KindURL {
+		return params
+	}
+	for _, part := range strings.Split(l.url.RawQuery, "&") {
+		if part == "" || strings.Contains(part, ";") {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			continue
+		}
+		value, err = url.QueryUnescape(value)
+		if err != nil {
+			continue
+		}
+		params = append(params, [2]string{key, value})
+	}
+	return params
+}
+
+// QueryParamNames returns the sorted names of the query params for a URL (an
+// empty slice for file paths and data URIs).
+func (l *Locator) QueryParamNames() []string {
+	if l.kind != KindURL {
+		return []string{}
+	}
+	query := l.url.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetQueryParam returns the first value for a query param and whether the param is present.
+func (l *Locator) GetQueryParam(param string) (string, bool) {
+	if l.kind != KindURL {
+		return "", false
+	}
+	values, ok := l.url.Query()[param]
+	if !ok || len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// Query returns a copy of the parsed query params (empty for file paths and
+// data URIs).
+func (l *Locator) Query() url.Values {
+	if l.kind != KindURL {
+		return url.Values{}
+	}
+	return l.url.Query()
+}
+
+// HasQuery checks if a URL has a query. A bare "?" counts as a query, even
+// though it has no params. File paths and data URIs never have a query (for a
+// data URI, a "?" is part of the data).
+func (l *Locator) HasQuery() bool {
+	if l.kind != KindURL {
+		return false
+	}
+	return l.url.RawQuery != "" || l.url.ForceQuery
+}
+
+// Userinfo returns the username and password for a URL and whether userinfo is present.
+func (l *Locator) Userinfo() (username string, password string, ok bool) {
+	if l.kind != KindURL || l.url.User == nil {
+		return "", "", false
+	}
+	password, _ = l.url.User.Password()
+	return l.url.User.Username(), password, true
+}
+
+// SetUserinfo updates the username and password for a URL (pass an empty
+// password to set only a username, or an empty username and password to clear
+// the userinfo like ClearUserinfo). File paths and data URIs are not modified.
+func (l *Locator) SetUserinfo(username string, password string) {
+	if l.kind != KindURL {
+		return
+	}
+	if username == "" && password == "" {
+		l.url.User = nil
+	} else if password != "" {
+		l.url.User = url.UserPassword(username, password)
+	} else {
+		l.url.User = url.User(username)
+	}
+}
+
+// ClearUserinfo removes the username and password from a URL.
+func (l *Locator) ClearUserinfo() {
+	if l.kind != KindURL {
+		return
+	}
+	l.url.User = nil
+}
+
+// Fragment returns the decoded fragment.
+func (l *Locator) Fragment() string {
+	return l.url.Fragment
+}
+
+// HasFragment checks if a URL has a fragment. A bare "#" counts as a fragment,
+// even though it is empty. File paths never have a fragment (even though
+// Fragment may return a JSON pointer for a file reference).
+func (l *Locator) HasFragment() bool {
+	if l.kind == KindFile {
+		return false
+	}
+	return l.url.Fragment != "" || l.forceFragment
+}
+
+// SetFragment updates the fragment (pass an empty string to remove the fragment).
+func (l *Locator) SetFragment(fragment string) {
+	l.url.Fragment = fragment
+	l.url.RawFragment = ""
+	l.forceFragment = false
+}
+
+// Kind returns the kind of locator.
+func (l *Locator) Kind() Kind {
+	return l.kind
+}
+
+// StripFragment creates a new locator with the fragment removed.
+func (l *Locator) StripFragment() *Locator {
+	clone := l.Clone()
+	clone.SetFragment("")
+	return clone
+}
+
 // IsFilepath checks if a locator is a file path.
 func (l *Locator) IsFilepath() bool {
-	return l.file
+	return l.Kind() == KindFile
 }
 
-// New creates a locator.
-func New(s string) (*Locator, error) {
+// IsURL checks if a locator is a URL (including a data URI). It is the inverse
+// of IsFilepath.
+func (l *Locator) IsURL() bool {
+	return !l.IsFilepath()
+}
+
+// FilePath returns the OS-native path for a file path.
+func (l *Locator) FilePath() (string, error) {
+	if l.kind != KindFile {
+		return "", fmt.Errorf("expected file path")
+	}
+	return l.style.fromSlash(l.url.Path), nil
+}
+
+// SlashPath returns the path of a file locator with forward slashes as the
+// separator, regardless of the OS (empty for URLs). This is the form used (with
+// percent-encoding) when a file locator is encoded as a JSON object.
+func (l *Locator) SlashPath() string {
+	if l.kind != KindFile {
+		return ""
+	}
+	return l.style.toSlash(l.url.Path)
+}
+
+// ToFileURL returns the file:// URL for a file path.
+func (l *Locator) ToFileURL() (string, error) {
+	if l.kind != KindFile {
+		return "", fmt.Errorf("expected file path")
+	}
+	path := l.style.toSlash(l.url.Path)
+	u := &url.URL{Scheme: "file", Path: path, Fragment: l.url.Fragment}
+	if l.style.windows() {
+		if strings.HasPrefix(path, "//") {
+			parts := strings.SplitN(path[2:], "/", 2)
+			u.Host = parts[0]
+			u.Path = "/"
+			if len(parts) > 1 {
+				u.Path += parts[1]
+			}
+		} else if !strings.HasPrefix(path, "/") {
+			u.Path = "/" + path
+		}
+	}
+	return u.String(), nil
+}
+
+// Base returns the last element of the path (trailing slashes are ignored and
+// an empty URL path is treated as the root).
+func (l *Locator) Base() string {
+	if l.kind == KindFile {
+		return l.style.base(l.url.Path)
+	}
+	if l.url.Path == "" {
+		return "/"
+	}
+	return path.Base(l.url.Path)
+}
+
+// Ext returns the extension of the last element of the path (including the
+// dot). A leading dot is not treated as an extension, so ".bashrc" has none.
+func (l *Locator) Ext() string {
+	base := strings.TrimLeft(l.Base(), ".")
+	return path.Ext(base)
+}
+
+// LooksLikeDir reports whether the locator appears to point at a directory.
+// This is a heuristic based only on the path: it is true when the path ends
+// with a separator, is empty or the root, or when the last element has no
+// extension (as reported by Ext). Data URLs never look like directories.
+func (l *Locator) LooksLikeDir() bool {
+	switch l.kind {
+	case KindData:
+		return false
+	case KindFile:
+		p := l.url.Path
+		if strings.HasSuffix(p, l.style.fromSlash("/")) || strings.HasSuffix(p, "/") {
+			return true
+		}
+	default:
+		if l.url.Path == "" || strings.HasSuffix(l.url.Path, "/") {
+			return true
+		}
+	}
+	return l.Ext() == ""
+}
+
+// PathDepth returns the number of segments in the cleaned path. The root (and
+// an empty URL path) has a depth of 0, and a trailing slash does not add a
+// segment. For Windows paths, the drive letter or UNC share is not counted.
+func (l *Locator) PathDepth() int {
+	var p string
+	switch l.kind {
+	case KindData:
+		return 0
+	case KindFile:
+		p = l.style.toSlash(l.style.clean(l.url.Path))
+		if l.style.windows() {
+			_, p = splitWindowsVolume(p)
+		}
+	default:
+		p = path.Clean("/" + l.url.EscapedPath())
+	}
+
+	depth := 0
+	for _, segment := range strings.Split(p, "/") {
+		if segment != "" {
+			depth++
+		}
+	}
+	return depth
+}
+
+// Scheme returns the URL scheme (or "file" for file paths).
+func (l *Locator) Scheme() string {
+	if l.kind == KindFile {
+		return "file"
+	}
+	return l.url.Scheme
+}
+
+// WithScheme creates a new URL locator with the scheme replaced. The scheme must
+// be allowed by the options used to create the locator, and the "file" and
+// "data" schemes cannot be used. The port is left unchanged. File paths and
+// data URIs return an error.
+func (l *Locator) WithScheme(scheme string) (*Locator, error) {
+	switch l.kind {
+	case KindFile:
+		return nil, fmt.Errorf("cannot set scheme on a file path")
+	case KindData:
+		return nil, fmt.Errorf("cannot set scheme on a data uri")
+	}
+	scheme = strings.ToLower(scheme)
+	if scheme == "file" || scheme == "data" {
+		return nil, fmt.Errorf("%w %s", ErrUnsupportedScheme, scheme)
+	}
+	clone := l.Clone()
+	clone.url.Scheme = scheme
+	if err := checkScheme(clone.url, l.options()); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// Host returns the URL host (or an empty string for file paths).
+func (l *Locator) Host() string {
+	if l.kind == KindFile {
+		return ""
+	}
+	return l.url.Host
+}
+
+// Hostname returns the URL host without a port (IPv6 brackets are removed).
+func (l *Locator) Hostname() string {
+	if l.kind == KindFile {
+		return ""
+	}
+	return l.url.Hostname()
+}
+
+// ExplicitPort returns the port included in the URL host (or an empty string
+// if there is none). See Port for a port that includes scheme defaults.
+func (l *Locator) ExplicitPort() string {
+	if l.kind == KindFile {
+		return ""
+	}
+	return l.url.Port()
+}
+
+// Port returns the URL port, or the default port for the scheme if none is
+// present (-1 if the port cannot be determined or for file paths).
+func (l *Locator) Port() int {
+	if l.kind == KindFile {
+		return -1
+	}
+	port := l.url.Port()
+	if port == "" {
+		port = defaultPorts[l.url.Scheme]
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// Path returns the decoded URL path (or the native path for file paths).
+func (l *Locator) Path() string {
+	return l.url.Path
+}
+
+// EscapedPath returns the percent-encoded path.
+func (l *Locator) EscapedPath() string {
+	return l.url.EscapedPath()
+}
+
+// URL returns a copy of the underlying URL. For file paths, the URL has no
+// scheme and the path is the native path.
+func (l *Locator) URL() *url.URL {
+	u := *l.url
+	return &u
+}
+
+// asciiSpace is the leading and trailing whitespace removed by New.
+const asciiSpace = " \t\n\v\f\r"
+
+// New creates a locator. Leading and trailing ASCII whitespace is removed from
+// the input (spaces within a URL are percent-encoded, and other whitespace
+// within a URL is an error) unless the WithStrict option is used. The host of
+// a URL is converted to lowercase.
+func New(s string, opts ...Option) (*Locator, error) {
+	return newLocator(s, newOptions(opts))
+}
+
+func newLocator(s string, o *options) (*Locator, error) {
+	if err := checkLength(s, o); err != nil {
+		return nil, err
+	}
+
+	input := s
+	if !o.strict {
+		input = strings.Trim(s, asciiSpace)
+	}
+	l, err := parse(input, o)
+	if err != nil {
+		return nil, err
+	}
+	l.raw = s
+	l.opts = o
+	l.forceFragment = l.kind != KindFile && l.url.Fragment == "" && strings.HasSuffix(input, "#")
+	return l, nil
+}
+
+// FromURL creates a locator from a parsed URL, with the same validation and
+// file path detection as New. The URL is copied, so later changes to it do not
+// affect the locator.
+func FromURL(u *url.URL, opts ...Option) (*Locator, error) {
+	if u == nil {
+		return nil, ErrMissingURL
+	}
+	c := *u
+	o := newOptions(opts)
+	l, err := fromURL(&c, o)
+	if err != nil {
+		return nil, err
+	}
+	l.raw = u.String()
+	l.opts = o
+	return l, nil
+}
+
+// NewFile creates a file locator from a path. Unlike New, the input is never
+// parsed as a URL and is checked against the configured path style instead of
+// the conventions of the current OS, so a Windows path can be used on any OS
+// with WithPathStyle(WindowsStyle). An error is returned if the path is not
+// absolute under that style.
+func NewFile(p string, opts ...Option) (*Locator, error) {
+	o := newOptions(opts)
+	if o.noFiles {
+		return nil, fmt.Errorf("file locators not allowed")
+	}
+	if err := checkLength(p, o); err != nil {
+		return nil, err
+	}
+
+	path := o.style.fromSlash(p)
+	if !o.style.isAbs(path) {
+		return nil, fmt.Errorf("%w: %s", ErrRelativePath, p)
+	}
+	loc := &Locator{
+		url:   &url.URL{Path: path},
+		kind:  KindFile,
+		raw:   p,
+		style: o.style,
+		opts:  o,
+	}
+	return loc, nil
+}
+
+// NewURL creates a URL locator. It is like New with WithoutFileLocators, so an
+// error is returned for file paths and file:// URLs as well as for inputs
+// without a scheme.
+func NewURL(s string, opts ...Option) (*Locator, error) {
+	o := newOptions(opts)
+	o.noFiles = true
+	return newLocator(s, o)
+}
+
+func parse(s string, o *options) (*Locator, error) {
+	if o.scheme != "" && looksLikeHost(s, o.style) {
+		s = o.scheme + "://" + s
+	}
+
+	if o.style.windows() && (isDrivePath(s) || isUNCPath(s)) {
+		if o.noFiles {
+			return nil, fmt.Errorf("file locators not allowed")
+		}
+		loc := &Locator{
+			url:   &url.URL{Path: o.style.fromSlash(s)},
+			kind:  KindFile,
+			style: o.style,
+		}
+		return loc, nil
+	}
+
+	if err := checkIPv6Host(s); err != nil {
+		return nil, err
+	}
+
+	if o.strict && !o.style.isAbs(s) {
+		if err := checkStrict(s); err != nil {
+			return nil, err
+		}
+	}
+
 	u, err := url.Parse(s)
 	if err != nil {
 		return nil, err
 	}
+	return fromURL(u, o)
+}
+
+func checkLength(s string, o *options) error {
+	if o.maxLength > 0 && len(s) > o.maxLength {
+		return fmt.Errorf("%w: length %d exceeds %d", ErrTooLong, len(s), o.maxLength)
+	}
+	return nil
+}
+
+// checkStrict checks that a URL only includes characters allowed by RFC 3986
+// and that each percent sign starts a valid escape.
+func checkStrict(s string) error {
+	for i, c := range s {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune(`"<>\^`+"`"+`{|}`, c) {
+			return fmt.Errorf("%w %q at position %d", ErrInvalidCharacter, c, i)
+		}
+		if c == '%' && (i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2])) {
+			return fmt.Errorf("%w %q at position %d", ErrInvalidCharacter, c, i)
+		}
+	}
+	return nil
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func fromURL(u *url.URL, o *options) (*Locator, error) {
+	if o.noFiles && (u.Scheme == "" || u.Scheme == "file") {
+		return nil, fmt.Errorf("file locators not allowed")
+	}
 
 	if u.Scheme == "" {
-		if !filepath.IsAbs(s) {
-			return nil, fmt.Errorf("expected absolute path")
+		if !o.style.isAbs(u.Path) {
+			return nil, ErrRelativePath
 		}
 		loc := &Locator{
-			url:  u,
-			file: true,
+			url:   u,
+			kind:  KindFile,
+			style: o.style,
 		}
 		return loc, nil
 	}
 
 	if u.Scheme == "file" {
 		path := u.Path
-		if runtime.GOOS == "windows" {
-			path = filepath.FromSlash(strings.TrimPrefix(path, "/"))
+		if o.style.windows() {
+			if u.Host != "" && u.Host != "localhost" {
+				path = o.style.fromSlash("//" + u.Host + path)
+			} else {
+				path = o.style.fromSlash(strings.TrimPrefix(path, "/"))
+			}
+		} else if u.Host != "" && u.Host != "localhost" {
+			return nil, fmt.Errorf("%w %s", ErrFileHost, u.Host)
 		}
+		u.Host = ""
 		u.Scheme = ""
 		u.Path = path
 		loc := &Locator{
-			url:  u,
-			file: true,
+			url:   u,
+			kind:  KindFile,
+			style: o.style,
 		}
 		return loc, nil
 	}
 
-	if u.Scheme != "http" && u.Scheme != "https" {
-		return nil, fmt.Errorf("unsupported scheme %s", u.Scheme)
+	if err := checkScheme(u, o); err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "data" {
+		return newData(u)
+	}
+
+	if o.noUser && u.User != nil {
+		return nil, ErrUserinfoNotAllowed
+	}
+
+	u.Host = strings.ToLower(u.Host)
+
+	return &Locator{url: u, style: o.style}, nil
+}
+
+// NewMany creates a locator for each input. The returned slice has the same
+// length as the inputs, with a nil entry for each input that failed. The
+// returned error joins the errors for all failed inputs.
+func NewMany(inputs []string, opts ...Option) ([]*Locator, error) {
+	locators := make([]*Locator, len(inputs))
+	var errs []error
+	for i, input := range inputs {
+		l, err := New(input, opts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("input %d (%q): %w", i, input, err))
+			continue
+		}
+		locators[i] = l
+	}
+	return locators, errors.Join(errs...)
+}
+
+// Build creates a URL locator from decoded components. Each path segment and
+// the query are percent-encoded as needed. Segments that are empty, ".", or
+// ".." are rejected. The result is validated as with NewURL, so the file
+// scheme is an error.
+func Build(scheme string, host string, pathSegments []string, query url.Values, opts ...Option) (*Locator, error) {
+	if scheme == "" {
+		return nil, fmt.Errorf("missing scheme")
 	}
 
-	return &Locator{url: u}, nil
+	escaped := make([]string, len(pathSegments))
+	for i, segment := range pathSegments {
+		if segment == "" || segment == "." || segment == ".." {
+			return nil, fmt.Errorf("invalid path segment %q", segment)
+		}
+		escaped[i] = url.PathEscape(segment)
+	}
+
+	u := &url.URL{
+		Scheme:   scheme,
+		Host:     host,
+		RawQuery: query.Encode(),
+	}
+	if len(pathSegments) > 0 {
+		u.Path = "/" + strings.Join(pathSegments, "/")
+		u.RawPath = "/" + strings.Join(escaped, "/")
+	}
+	return NewURL(u.String(), opts...)
 }
 
-// Resolve creates a new locator from a base.
+// MustNew creates a locator and panics on error. It is intended for use with
+// inputs known at compile time, such as package-level variables and test tables.
+func MustNew(s string, opts ...Option) *Locator {
+	l, err := New(s, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+func looksLikeHost(s string, style PathStyle) bool {
+	if strings.Contains(s, "://") || strings.HasPrefix(s, "/") || style.isAbs(s) {
+		return false
+	}
+
+	authority := s
+	if i := strings.IndexAny(s, "/?#"); i >= 0 {
+		authority = s[:i]
+	}
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		authority = authority[i+1:]
+	}
+
+	host, port := authority, ""
+	if i := strings.LastIndex(authority, ":"); i >= 0 {
+		host, port = authority[:i], authority[i+1:]
+		if port == "" || strings.Trim(port, "0123456789") != "" {
+			return false
+		}
+	}
+	if host == "" {
+		return false
+	}
+
+	return host == "localhost" || strings.Contains(host, ".") || port != ""
+}
+
+// JoinPath creates a new locator with path segments appended to the path. For
+// URLs, each segment is percent-encoded. Segments that are empty, ".", "..", or
+// that contain a path separator are rejected. For URLs, the query and fragment
+// are kept. Data URIs have no path and return an error.
+func (l *Locator) JoinPath(segments ...string) (*Locator, error) {
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, `/\`) {
+			return nil, fmt.Errorf("invalid path segment %q", segment)
+		}
+	}
+
+	clone := l.Clone()
+	switch l.kind {
+	case KindData:
+		return nil, fmt.Errorf("cannot join a path to a data uri")
+	case KindFile:
+		clone.url.Path = l.style.join(append([]string{clone.url.Path}, segments...)...)
+		return clone, nil
+	}
+
+	escaped := clone.url.EscapedPath()
+	for _, segment := range segments {
+		if !strings.HasSuffix(escaped, "/") {
+			escaped += "/"
+		}
+		escaped += url.PathEscape(segment)
+	}
+	if err := setEscapedPath(clone.url, escaped); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// TrimTrailingSlash creates a new locator with a single trailing slash removed
+// from the path. The root path is left unchanged.
+func (l *Locator) TrimTrailingSlash() *Locator {
+	clone := l.Clone()
+	if l.kind == KindFile {
+		p := clone.url.Path
+		sep := l.style.separator()
+		root := l.style.volumeName(p) + sep
+		if len(p) > len(root) && (strings.HasSuffix(p, sep) || strings.HasSuffix(p, "/")) {
+			clone.url.Path = p[:len(p)-1]
+		}
+		return clone
+	}
+
+	escaped := clone.url.EscapedPath()
+	if len(escaped) > 1 && strings.HasSuffix(escaped, "/") {
+		_ = setEscapedPath(clone.url, escaped[:len(escaped)-1])
+	}
+	return clone
+}
+
+// EnsureTrailingSlash creates a new locator with a trailing slash added to the
+// path if it does not already have one (a separator for file paths). This is
+// useful for treating a locator as a directory when resolving references.
+// For URLs, an empty path becomes the root.
+func (l *Locator) EnsureTrailingSlash() *Locator {
+	clone := l.Clone()
+	switch l.kind {
+	case KindData:
+		return clone
+	case KindFile:
+		p := clone.url.Path
+		sep := l.style.fromSlash("/")
+		if !strings.HasSuffix(p, sep) && !strings.HasSuffix(p, "/") {
+			clone.url.Path = p + sep
+		}
+		return clone
+	}
+
+	escaped := clone.url.EscapedPath()
+	if !strings.HasSuffix(escaped, "/") {
+		_ = setEscapedPath(clone.url, escaped+"/")
+	}
+	return clone
+}
+
+// Clean creates a new locator with a cleaned path. Repeated separators and "."
+// segments are removed, and ".." segments are applied lexically. A trailing
+// separator is kept, since it marks a directory when resolving references.
+// Data URIs are returned unchanged.
+func (l *Locator) Clean() *Locator {
+	clone := l.Clone()
+	switch l.kind {
+	case KindData:
+		return clone
+	case KindFile:
+		p := clone.url.Path
+		cleaned := l.style.clean(p)
+		sep := l.style.fromSlash("/")
+		if (strings.HasSuffix(p, sep) || strings.HasSuffix(p, "/")) && !strings.HasSuffix(cleaned, sep) {
+			cleaned += sep
+		}
+		clone.url.Path = cleaned
+		return clone
+	}
+
+	escaped := clone.url.EscapedPath()
+	if escaped == "" {
+		return clone
+	}
+	cleaned := path.Clean(escaped)
+	if strings.HasSuffix(escaped, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	_ = setEscapedPath(clone.url, cleaned)
+	return clone
+}
+
+func setEscapedPath(u *url.URL, escaped string) error {
+	unescaped, err := url.PathUnescape(escaped)
+	if err != nil {
+		return err
+	}
+	u.Path = unescaped
+	u.RawPath = escaped
+	return nil
+}
+
+// Dir creates a new locator for the parent directory. The returned path ends
+// with a separator so it can be used as a base for Resolve. For URLs, the query
+// and fragment are removed. Data URIs have no directory and return an error.
+func (l *Locator) Dir() (*Locator, error) {
+	if l.kind == KindData {
+		return nil, fmt.Errorf("cannot get the directory of a data uri")
+	}
+	if l.kind == KindFile {
+		dir := l.style.dir(l.style.clean(l.url.Path))
+		if sep := l.style.separator(); !strings.HasSuffix(dir, sep) {
+			dir += sep
+		}
+		loc := &Locator{
+			url:   &url.URL{Path: dir},
+			kind:  KindFile,
+			style: l.style,
+			opts:  l.opts,
+		}
+		return loc, nil
+	}
+
+	escaped := strings.TrimSuffix(l.url.EscapedPath(), "/")
+	dir := escaped[:strings.LastIndex(escaped, "/")+1]
+	if dir == "" {
+		dir = "/"
+	}
+	u := *l.url
+	if err := setEscapedPath(&u, dir); err != nil {
+		return nil, err
+	}
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	u.RawFragment = ""
+	return &Locator{url: &u, style: l.style, opts: l.opts}, nil
+}
+
+// AncestorDirs returns the parent directory of the locator, followed by each
+// of its ancestors up to and including the root. Each directory ends with a
+// separator, as with Dir. Data URIs have no ancestors.
+func (l *Locator) AncestorDirs() []*Locator {
+	if l.kind == KindData {
+		return nil
+	}
+
+	var dirs []*Locator
+	current := l
+	for {
+		dir, err := current.Dir()
+		if err != nil || (len(dirs) > 0 && dir.url.Path == current.url.Path) {
+			return dirs
+		}
+		dirs = append(dirs, dir)
+		current = dir
+	}
+}
+
+// Resolve creates a new locator from a base. As with URLs, a file path base
+// that ends with a separator is treated as a directory, so relative references
+// are resolved within it instead of its parent. For file paths, the fragment
+// of the reference is kept on the resolved locator and a reference with a
+// query is an error (a "?" in a file name must be escaped as "%3F"). The
+// reference is a URI reference, so "%" and "#" in a file name must also be
+// escaped. With a Windows path style, a drive letter or UNC
+// reference is always resolved as an absolute file path. A data URI base is
+// self-contained, so relative references resolve to the base itself. A URL
+// base without a host is resolved following RFC 3986, except that a path
+// cannot be resolved against an opaque URL (e.g. "https:path").
+//
+// The options used to create the base also apply to the reference and are
+// kept on the resolved locator. A reference with a scheme is created as with
+// New. The WithStrict, WithMaxLength, and WithoutUserinfo options also apply
+// to relative references, but WithDefaultScheme does not (a relative
+// reference like "file.json" would otherwise look like a host).
 func (base *Locator) Resolve(s string) (*Locator, error) {
+	o := base.options()
+
+	// drive letters would otherwise be parsed as a URL scheme
+	if base.style.windows() && (isDrivePath(s) || isUNCPath(s)) {
+		return newLocator(s, o)
+	}
+
+	if err := checkLength(s, o); err != nil {
+		return nil, err
+	}
+	if !o.strict {
+		s = strings.Trim(s, asciiSpace)
+	}
+
 	u, err := url.Parse(s)
 	if err != nil {
 		return nil, err
 	}
 
 	if u.Scheme != "" {
-		return New(s)
+		return newLocator(s, o)
+	}
+
+	if base.kind == KindData {
+		return base.Clone(), nil
 	}
 
-	if base.file {
-		if filepath.IsAbs(s) {
+	if base.kind == KindFile {
+		// a "?" in a file name must be escaped as "%3F" in the reference
+		if u.RawQuery != "" || u.ForceQuery {
+			return nil, fmt.Errorf("cannot resolve a query against a file path")
+		}
+		if u.Path == "" {
+			loc := base.Clone()
+			loc.SetFragment(u.Fragment)
+			return loc, nil
+		}
+
+		if base.style.isAbs(u.Path) {
 			loc := &Locator{
-				url:  u,
-				file: true,
+				url:   &url.URL{Path: base.style.clean(u.Path), Fragment: u.Fragment},
+				kind:  KindFile,
+				style: base.style,
+				opts:  base.opts,
 			}
 			return loc, nil
 		}
 
-		baseDir := filepath.Dir(base.url.Path)
-		path := filepath.Join(baseDir, s)
+		// Join also cleans the result, so ".." segments never remain in the path
+		baseDir := base.style.dir(base.url.Path)
+		path := base.style.join(baseDir, u.Path)
 		loc := &Locator{
-			url:  &url.URL{Path: path},
-			file: true,
+			url:   &url.URL{Path: path, Fragment: u.Fragment},
+			kind:  KindFile,
+			style: base.style,
+			opts:  base.opts,
 		}
 		return loc, nil
 	}
 
+	if base.url.Opaque != "" && u.Host == "" && u.Path != "" {
+		return nil, fmt.Errorf("cannot resolve a path against an opaque url")
+	}
+
+	if o.strict {
+		if err := checkStrict(s); err != nil {
+			return nil, err
+		}
+	}
+	if o.noUser && u.User != nil {
+		return nil, ErrUserinfoNotAllowed
+	}
+
 	resolved := base.url.ResolveReference(u)
+	// per RFC 3986, the fragment always comes from the reference
+	resolved.Fragment = u.Fragment
+	resolved.RawFragment = u.RawFragment
+	// a base without an authority (e.g. "https:/path") resolves to a URL without one
+	resolved.OmitHost = base.url.OmitHost
+	resolved.Host = strings.ToLower(resolved.Host)
 	loc := &Locator{
-		url:  resolved,
-		file: false,
+		url:           resolved,
+		kind:          KindURL,
+		style:         base.style,
+		opts:          base.opts,
+		forceFragment: u.Fragment == "" && strings.HasSuffix(s, "#"),
 	}
 	return loc, nil
 }
+
+// MustResolve resolves a reference and panics on error. Like MustNew, it is
+// intended for use with references known at compile time, such as test tables.
+func (base *Locator) MustResolve(s string) *Locator {
+	l, err := base.Resolve(s)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Rel returns a reference that resolves to the target when resolved against
+// this locator. Both locators must be file paths or URLs with the same scheme
+// and host. For file paths, the reference uses forward slashes and is
+// percent-encoded like a URL path (e.g. "100%25.txt"). Data URIs return an
+// error.
+func (base *Locator) Rel(target *Locator) (string, error) {
+	if base.kind != target.kind {
+		return "", fmt.Errorf("cannot relate a file path and a URL")
+	}
+	if base.kind == KindData {
+		return "", fmt.Errorf("cannot relate data uris")
+	}
+
+	if base.kind == KindFile {
+		rel, err := base.style.rel(base.style.dir(base.url.Path), target.url.Path)
+		if err != nil {
+			return "", err
+		}
+		// the reference is parsed as a URL by Resolve
+		rel = (&url.URL{Path: base.style.toSlash(rel)}).EscapedPath()
+		if strings.Contains(strings.SplitN(rel, "/", 2)[0], ":") {
+			rel = "./" + rel
+		}
+		if target.url.Fragment != "" {
+			rel += "#" + target.url.EscapedFragment()
+		}
+		return rel, nil
+	}
+
+	if base.url.Scheme != target.url.Scheme || base.url.Host != target.url.Host || base.url.User.String() != target.url.User.String() {
+		return "", fmt.Errorf("cannot relate URLs with a different scheme or host")
+	}
+
+	basePath := base.url.EscapedPath()
+	if basePath == "" {
+		basePath = "/"
+	}
+	targetPath := target.url.EscapedPath()
+	if targetPath == "" {
+		targetPath = "/"
+	}
+
+	baseSegments := strings.Split(basePath[:strings.LastIndex(basePath, "/")], "/")[1:]
+	targetSegments := strings.Split(targetPath, "/")[1:]
+
+	common := 0
+	for common < len(baseSegments) && common < len(targetSegments)-1 {
+		if baseSegments[common] != targetSegments[common] {
+			break
+		}
+		common++
+	}
+
+	rel := strings.Repeat("../", len(baseSegments)-common) + strings.Join(targetSegments[common:], "/")
+	if rel == "" || strings.HasPrefix(rel, "/") || strings.Contains(strings.SplitN(rel, "/", 2)[0], ":") {
+		rel = "./" + rel
+	}
+
+	if target.url.RawQuery != "" || target.url.ForceQuery {
+		rel += "?" + target.url.RawQuery
+	}
+	if target.url.Fragment != "" {
+		rel += "#" + target.url.EscapedFragment()
+	}
+
+	return rel, nil
+}
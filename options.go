@@ -0,0 +1,91 @@
+package normurl
+
+import "strings"
+
+// Option configures how a locator is created.
+type Option func(*options)
+
+type options struct {
+	schemes   map[string]bool
+	noFiles   bool
+	scheme    string
+	noUser    bool
+	style     PathStyle
+	strict    bool
+	maxLength int
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		schemes: map[string]bool{
+			"http":  true,
+			"https": true,
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithAllowedSchemes allows URL schemes in addition to the default http and https.
+// Allowing the "data" scheme creates data URI locators (see KindData).
+func WithAllowedSchemes(schemes ...string) Option {
+	return func(o *options) {
+		for _, scheme := range schemes {
+			o.schemes[strings.ToLower(scheme)] = true
+		}
+	}
+}
+
+// WithoutFileLocators rejects file paths and file:// URLs.
+func WithoutFileLocators() Option {
+	return func(o *options) {
+		o.noFiles = true
+	}
+}
+
+// WithDefaultScheme adds a scheme to inputs that look like a host without a
+// scheme (e.g. "example.com/path" or "localhost:8080/path"). An input is treated
+// as a host if it is not an absolute path and the part before the first slash
+// contains a dot, is "localhost", or ends with a numeric port.
+func WithDefaultScheme(scheme string) Option {
+	return func(o *options) {
+		o.scheme = strings.ToLower(scheme)
+	}
+}
+
+// WithoutUserinfo rejects URLs that include a username or password.
+func WithoutUserinfo() Option {
+	return func(o *options) {
+		o.noUser = true
+	}
+}
+
+// WithPathStyle sets the style used to interpret file paths and file:// URLs
+// (by default, the style of the current OS is used). The style is kept on the
+// locator and used by methods that work with the path, such as Resolve, Dir,
+// and Equal.
+func WithPathStyle(style PathStyle) Option {
+	return func(o *options) {
+		o.style = style
+	}
+}
+
+// WithStrict rejects URLs with characters that are not allowed by RFC 3986
+// (such as spaces, control characters, and non-ASCII characters) instead of
+// percent-encoding them. Surrounding whitespace is also rejected. File paths
+// are not affected.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithMaxLength rejects inputs longer than n bytes before parsing. By default,
+// there is no limit.
+func WithMaxLength(n int) Option {
+	return func(o *options) {
+		o.maxLength = n
+	}
+}
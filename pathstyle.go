@@ -0,0 +1,241 @@
+package normurl
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PathStyle determines how file paths are interpreted.
+type PathStyle int
+
+const (
+	// NativeStyle interprets file paths using the conventions of the current OS.
+	NativeStyle PathStyle = iota
+	// PosixStyle interprets file paths using POSIX conventions.
+	PosixStyle
+	// WindowsStyle interprets file paths using Windows conventions.
+	WindowsStyle
+)
+
+// NormalizeForOS creates a new locator with a file path converted to the
+// conventions of the current OS. This is useful for locators created with a
+// different path style or decoded from data written on another OS. An error is
+// returned if the converted path is not absolute. URLs are returned unchanged.
+func (l *Locator) NormalizeForOS() (*Locator, error) {
+	clone := l.Clone()
+	if l.kind != KindFile {
+		return clone, nil
+	}
+
+	p := filepath.FromSlash(l.style.toSlash(l.url.Path))
+	if !filepath.IsAbs(p) {
+		return nil, fmt.Errorf("%w: %s", ErrRelativePath, p)
+	}
+	clone.url.Path = p
+	clone.style = NativeStyle
+	return clone, nil
+}
+
+func (s PathStyle) windows() bool {
+	return s == WindowsStyle || (s == NativeStyle && runtime.GOOS == "windows")
+}
+
+func (s PathStyle) isAbs(path string) bool {
+	switch s {
+	case PosixStyle:
+		return strings.HasPrefix(path, "/")
+	case WindowsStyle:
+		return isDrivePath(path) || isUNCPath(path)
+	default:
+		return filepath.IsAbs(path)
+	}
+}
+
+func (s PathStyle) fromSlash(path string) string {
+	switch s {
+	case PosixStyle:
+		return path
+	case WindowsStyle:
+		return strings.ReplaceAll(path, "/", `\`)
+	default:
+		return filepath.FromSlash(path)
+	}
+}
+
+func (s PathStyle) toSlash(path string) string {
+	switch s {
+	case PosixStyle:
+		return path
+	case WindowsStyle:
+		return strings.ReplaceAll(path, `\`, "/")
+	default:
+		return filepath.ToSlash(path)
+	}
+}
+
+func (s PathStyle) clean(p string) string {
+	switch s {
+	case PosixStyle:
+		return path.Clean(p)
+	case WindowsStyle:
+		return s.fromSlash(cleanWindows(s.toSlash(p)))
+	default:
+		return filepath.Clean(p)
+	}
+}
+
+func (s PathStyle) separator() string {
+	switch s {
+	case PosixStyle:
+		return "/"
+	case WindowsStyle:
+		return `\`
+	default:
+		return string(filepath.Separator)
+	}
+}
+
+func (s PathStyle) dir(p string) string {
+	switch s {
+	case PosixStyle:
+		return path.Dir(p)
+	case WindowsStyle:
+		volume, rest := splitWindowsVolume(s.toSlash(p))
+		return s.fromSlash(volume + path.Dir(rest))
+	default:
+		return filepath.Dir(p)
+	}
+}
+
+func (s PathStyle) base(p string) string {
+	switch s {
+	case PosixStyle:
+		return path.Base(p)
+	case WindowsStyle:
+		_, rest := splitWindowsVolume(s.toSlash(p))
+		return s.fromSlash(path.Base(rest))
+	default:
+		return filepath.Base(p)
+	}
+}
+
+func (s PathStyle) join(elem ...string) string {
+	switch s {
+	case PosixStyle:
+		return path.Join(elem...)
+	case WindowsStyle:
+		parts := make([]string, 0, len(elem))
+		for _, e := range elem {
+			if e != "" {
+				parts = append(parts, s.toSlash(e))
+			}
+		}
+		if len(parts) == 0 {
+			return ""
+		}
+		return s.fromSlash(cleanWindows(strings.Join(parts, "/")))
+	default:
+		return filepath.Join(elem...)
+	}
+}
+
+func (s PathStyle) volumeName(p string) string {
+	switch s {
+	case PosixStyle:
+		return ""
+	case WindowsStyle:
+		volume, _ := splitWindowsVolume(s.toSlash(p))
+		return s.fromSlash(volume)
+	default:
+		return filepath.VolumeName(p)
+	}
+}
+
+// rel returns a relative path from basepath to targpath like filepath.Rel.
+// Both paths are expected to be absolute.
+func (s PathStyle) rel(basepath, targpath string) (string, error) {
+	if s == NativeStyle {
+		return filepath.Rel(basepath, targpath)
+	}
+
+	base := s.toSlash(s.clean(basepath))
+	targ := s.toSlash(s.clean(targpath))
+	if s.windows() {
+		var baseVolume, targVolume string
+		baseVolume, base = splitWindowsVolume(base)
+		targVolume, targ = splitWindowsVolume(targ)
+		if !strings.EqualFold(baseVolume, targVolume) {
+			return "", fmt.Errorf("Rel: can't make %s relative to %s", targpath, basepath)
+		}
+	}
+	return s.fromSlash(relSlash(base, targ, s.windows())), nil
+}
+
+// relSlash returns a relative path between two cleaned, slash separated,
+// rooted paths. If fold is true, segments are compared without regard to case.
+func relSlash(base, targ string, fold bool) string {
+	baseSegments := strings.FieldsFunc(base, func(r rune) bool { return r == '/' })
+	targSegments := strings.FieldsFunc(targ, func(r rune) bool { return r == '/' })
+
+	i := 0
+	for i < len(baseSegments) && i < len(targSegments) {
+		b, t := baseSegments[i], targSegments[i]
+		if b != t && !(fold && strings.EqualFold(b, t)) {
+			break
+		}
+		i++
+	}
+
+	parts := make([]string, 0, len(baseSegments)-i+len(targSegments)-i)
+	for range baseSegments[i:] {
+		parts = append(parts, "..")
+	}
+	parts = append(parts, targSegments[i:]...)
+	if len(parts) == 0 {
+		return "."
+	}
+	return strings.Join(parts, "/")
+}
+
+// cleanWindows cleans a slash separated Windows path, keeping the drive letter
+// or UNC host and share as the root.
+func cleanWindows(p string) string {
+	volume, rest := splitWindowsVolume(p)
+	return volume + path.Clean(rest)
+}
+
+// splitWindowsVolume splits a slash separated Windows path into the drive
+// letter or UNC host and share and the rest of the path.
+func splitWindowsVolume(p string) (string, string) {
+	if isDrivePath(p) {
+		return p[:2], p[2:]
+	}
+	if strings.HasPrefix(p, "//") {
+		parts := strings.SplitN(p[2:], "/", 3)
+		if len(parts) > 1 {
+			rest := "/"
+			if len(parts) > 2 {
+				rest += parts[2]
+			}
+			return "//" + parts[0] + "/" + parts[1], rest
+		}
+	}
+	return "", p
+}
+
+// isDrivePath checks if a string starts with a Windows drive letter (e.g. `C:\` or "C:/").
+func isDrivePath(s string) bool {
+	if len(s) < 3 || s[1] != ':' || (s[2] != '\\' && s[2] != '/') {
+		return false
+	}
+	c := s[0]
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// isUNCPath checks if a string starts with a Windows UNC prefix (e.g. `\\server\share`).
+func isUNCPath(s string) bool {
+	return strings.HasPrefix(s, `\\`)
+}
@@ -0,0 +1,594 @@
+package normurl_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tschaub/normurl"
+)
+
+func TestWithPathStyle(t *testing.T) {
+	cases := []struct {
+		input   string
+		style   normurl.PathStyle
+		path    string
+		fileURL string
+		err     error
+	}{
+		{
+			input:   "file:///C:/x",
+			style:   normurl.WindowsStyle,
+			path:    `C:\x`,
+			fileURL: "file:///C:/x",
+		},
+		{
+			input:   "file:///C:/path/with%20space/file.txt",
+			style:   normurl.WindowsStyle,
+			path:    `C:\path\with space\file.txt`,
+			fileURL: "file:///C:/path/with%20space/file.txt",
+		},
+		{
+			input:   `C:\Users\me\file.txt`,
+			style:   normurl.WindowsStyle,
+			path:    `C:\Users\me\file.txt`,
+			fileURL: "file:///C:/Users/me/file.txt",
+		},
+		{
+			input:   "C:/Users/me/file.txt",
+			style:   normurl.WindowsStyle,
+			path:    `C:\Users\me\file.txt`,
+			fileURL: "file:///C:/Users/me/file.txt",
+		},
+		{
+			input:   `\\server\share\file.txt`,
+			style:   normurl.WindowsStyle,
+			path:    `\\server\share\file.txt`,
+			fileURL: "file://server/share/file.txt",
+		},
+		{
+			input:   "file://server/share/file.txt",
+			style:   normurl.WindowsStyle,
+			path:    `\\server\share\file.txt`,
+			fileURL: "file://server/share/file.txt",
+		},
+		{
+			input: "/path/to/file",
+			style: normurl.WindowsStyle,
+			err:   errors.New("expected absolute path"),
+		},
+		{
+			input:   "/path/to/file",
+			style:   normurl.PosixStyle,
+			path:    "/path/to/file",
+			fileURL: "file:///path/to/file",
+		},
+		{
+			input:   "file:///path/to/file",
+			style:   normurl.PosixStyle,
+			path:    "/path/to/file",
+			fileURL: "file:///path/to/file",
+		},
+		{
+			input: "C:/Users/me/file.txt",
+			style: normurl.PosixStyle,
+			err:   errors.New("unsupported scheme c"),
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			l, err := normurl.New(c.input, normurl.WithPathStyle(c.style))
+			if c.err != nil {
+				assert.Nil(t, l)
+				assert.EqualError(t, err, c.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, l.IsFilepath())
+
+			path, err := l.FilePath()
+			require.NoError(t, err)
+			assert.Equal(t, c.path, path)
+
+			fileURL, err := l.ToFileURL()
+			require.NoError(t, err)
+			assert.Equal(t, c.fileURL, fileURL)
+		})
+	}
+}
+
+func TestWithPathStyleNative(t *testing.T) {
+	cases := []string{
+		"/path/to/file",
+		"file:///path/to/file",
+	}
+
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			native, nativeErr := normurl.New(c, normurl.WithPathStyle(normurl.NativeStyle))
+			implicit, implicitErr := normurl.New(c)
+			assert.Equal(t, implicitErr, nativeErr)
+			if implicitErr == nil {
+				assert.Equal(t, implicit.String(), native.String())
+			}
+		})
+	}
+}
+
+func TestResolveDrivePath(t *testing.T) {
+	cases := []struct {
+		base  string
+		input string
+		path  string
+	}{
+		{
+			base:  `C:\dir\file.txt`,
+			input: "C:/other.txt",
+			path:  `C:\other.txt`,
+		},
+		{
+			base:  `C:\dir\file.txt`,
+			input: `D:\other\file.txt`,
+			path:  `D:\other\file.txt`,
+		},
+		{
+			base:  "https://example.com/dir/file.txt",
+			input: "C:/other.txt",
+			path:  `C:\other.txt`,
+		},
+		{
+			base:  "https://example.com/dir/file.txt",
+			input: `\\server\share\file.txt`,
+			path:  `\\server\share\file.txt`,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			base, err := normurl.New(c.base, normurl.WithPathStyle(normurl.WindowsStyle))
+			require.NoError(t, err)
+
+			resolved, err := base.Resolve(c.input)
+			require.NoError(t, err)
+			assert.True(t, resolved.IsFilepath())
+
+			path, err := resolved.FilePath()
+			require.NoError(t, err)
+			assert.Equal(t, c.path, path)
+		})
+	}
+}
+
+func TestResolveDrivePathPosix(t *testing.T) {
+	base, err := normurl.New("https://example.com/dir/file.txt", normurl.WithPathStyle(normurl.PosixStyle))
+	require.NoError(t, err)
+
+	_, err = base.Resolve("C:/other.txt")
+	assert.EqualError(t, err, "unsupported scheme c")
+}
+
+func TestCleanWindowsStyle(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    `C:\a\.\b\\c`,
+			expected: `C:\a\b\c`,
+		},
+		{
+			input:    "C:/a/b/../c/",
+			expected: `C:\a\c\`,
+		},
+		{
+			input:    `C:\..\a`,
+			expected: `C:\a`,
+		},
+		{
+			input:    `\\server\share\a\..\..\b`,
+			expected: `\\server\share\b`,
+		},
+		{
+			input:    "file:///C:/a//b",
+			expected: `C:\a\b`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input, normurl.WithPathStyle(normurl.WindowsStyle))
+			require.NoError(t, err)
+
+			path, err := l.Clean().FilePath()
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, path)
+		})
+	}
+}
+
+func TestFileURLHost(t *testing.T) {
+	cases := []struct {
+		input string
+		style normurl.PathStyle
+		path  string
+		err   error
+	}{
+		{
+			input: "file:///abs/file.txt",
+			style: normurl.PosixStyle,
+			path:  "/abs/file.txt",
+		},
+		{
+			input: "file://localhost/abs/file.txt",
+			style: normurl.PosixStyle,
+			path:  "/abs/file.txt",
+		},
+		{
+			input: "file://host/abs/file.txt",
+			style: normurl.PosixStyle,
+			err:   normurl.ErrFileHost,
+		},
+		{
+			input: "file:///C:/abs/file.txt",
+			style: normurl.WindowsStyle,
+			path:  `C:\abs\file.txt`,
+		},
+		{
+			input: "file://localhost/C:/abs/file.txt",
+			style: normurl.WindowsStyle,
+			path:  `C:\abs\file.txt`,
+		},
+		{
+			input: "file://host/abs/file.txt",
+			style: normurl.WindowsStyle,
+			path:  `\\host\abs\file.txt`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input, normurl.WithPathStyle(c.style))
+			if c.err != nil {
+				assert.ErrorIs(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			path, err := l.FilePath()
+			require.NoError(t, err)
+			assert.Equal(t, c.path, path)
+		})
+	}
+}
+
+func TestNormalizeForOS(t *testing.T) {
+	cases := []struct {
+		input string
+		style normurl.PathStyle
+		goos  string
+		path  string
+		err   error
+	}{
+		{
+			input: "/path/to/file.txt",
+			style: normurl.PosixStyle,
+			goos:  "!windows",
+			path:  "/path/to/file.txt",
+		},
+		{
+			input: "/path/to/file.txt",
+			style: normurl.PosixStyle,
+			goos:  "windows",
+			err:   normurl.ErrRelativePath,
+		},
+		{
+			input: `C:\path\to\file.txt`,
+			style: normurl.WindowsStyle,
+			goos:  "windows",
+			path:  `C:\path\to\file.txt`,
+		},
+		{
+			input: `C:\path\to\file.txt`,
+			style: normurl.WindowsStyle,
+			goos:  "!windows",
+			err:   normurl.ErrRelativePath,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			skipUnlessGOOS(t, c.goos)
+
+			l, err := normurl.New(c.input, normurl.WithPathStyle(c.style))
+			require.NoError(t, err)
+
+			normalized, err := l.NormalizeForOS()
+			if c.err != nil {
+				assert.ErrorIs(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			path, err := normalized.FilePath()
+			require.NoError(t, err)
+			assert.Equal(t, c.path, path)
+		})
+	}
+}
+
+func TestNormalizeForOSUnmarshalJSON(t *testing.T) {
+	skipUnlessGOOS(t, "!windows")
+
+	l := &normurl.Locator{}
+	require.NoError(t, json.Unmarshal([]byte(`{"Url": "/path/to/file.txt", "File": true}`), l))
+
+	normalized, err := l.NormalizeForOS()
+	require.NoError(t, err)
+
+	path, err := normalized.FilePath()
+	require.NoError(t, err)
+	assert.Equal(t, "/path/to/file.txt", path)
+}
+
+func TestNormalizeForOSURL(t *testing.T) {
+	l, err := normurl.New("https://example.com/path")
+	require.NoError(t, err)
+
+	normalized, err := l.NormalizeForOS()
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/path", normalized.String())
+}
+
+func TestPathDepthWindowsStyle(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int
+	}{
+		{
+			input:    `C:\`,
+			expected: 0,
+		},
+		{
+			input:    `C:\a\b\c`,
+			expected: 3,
+		},
+		{
+			input:    `C:\a\b\c\`,
+			expected: 3,
+		},
+		{
+			input:    `C:\a\..\b`,
+			expected: 1,
+		},
+		{
+			input:    `\\server\share`,
+			expected: 0,
+		},
+		{
+			input:    `\\server\share\a\b`,
+			expected: 2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input, normurl.WithPathStyle(normurl.WindowsStyle))
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, l.PathDepth())
+		})
+	}
+}
+
+func TestSlashPathWindowsStyle(t *testing.T) {
+	l, err := normurl.New(`C:\path\to\file`, normurl.WithPathStyle(normurl.WindowsStyle))
+	require.NoError(t, err)
+	assert.Equal(t, "C:/path/to/file", l.SlashPath())
+
+	l, err = normurl.New("/path/to/file", normurl.WithPathStyle(normurl.PosixStyle))
+	require.NoError(t, err)
+	assert.Equal(t, "/path/to/file", l.SlashPath())
+}
+
+func TestResolveWindowsStyle(t *testing.T) {
+	cases := []struct {
+		input    string
+		path     string
+		fragment string
+	}{
+		{
+			input: "d.txt",
+			path:  `C:\a\b\d.txt`,
+		},
+		{
+			input: "../d.txt",
+			path:  `C:\a\d.txt`,
+		},
+		{
+			input: "../../../../d.txt",
+			path:  `C:\d.txt`,
+		},
+		{
+			input: `sub\d.txt`,
+			path:  `C:\a\b\sub\d.txt`,
+		},
+		{
+			input:    "./schema.json#/definitions/x",
+			path:     `C:\a\b\schema.json`,
+			fragment: "/definitions/x",
+		},
+		{
+			input: "D:/other.txt",
+			path:  `D:\other.txt`,
+		},
+	}
+
+	base, err := normurl.New("file:///C:/a/b/c.txt", normurl.WithPathStyle(normurl.WindowsStyle))
+	require.NoError(t, err)
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			resolved, err := base.Resolve(c.input)
+			require.NoError(t, err)
+			assert.True(t, resolved.IsFilepath())
+
+			path, err := resolved.FilePath()
+			require.NoError(t, err)
+			assert.Equal(t, c.path, path)
+			assert.Equal(t, c.fragment, resolved.Fragment())
+		})
+	}
+}
+
+func TestDirWindowsStyle(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "file:///C:/a/b/c.txt",
+			expected: `C:\a\b\`,
+		},
+		{
+			input:    `C:\a\b\`,
+			expected: `C:\a\`,
+		},
+		{
+			input:    `C:\a`,
+			expected: `C:\`,
+		},
+		{
+			input:    `C:\`,
+			expected: `C:\`,
+		},
+		{
+			input:    `\\server\share\a\b`,
+			expected: `\\server\share\a\`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input, normurl.WithPathStyle(normurl.WindowsStyle))
+			require.NoError(t, err)
+
+			dir, err := l.Dir()
+			require.NoError(t, err)
+
+			path, err := dir.FilePath()
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, path)
+		})
+	}
+}
+
+func TestEqualWindowsStyle(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		equal    bool
+		fold     bool
+		ancestor bool
+	}{
+		{
+			a:        `C:\a\b\c.txt`,
+			b:        "file:///C:/a/b/c.txt",
+			equal:    true,
+			fold:     true,
+			ancestor: true,
+		},
+		{
+			a:        `C:\a\x\..\b\c.txt`,
+			b:        `C:\a\b\c.txt`,
+			equal:    true,
+			fold:     true,
+			ancestor: true,
+		},
+		{
+			a:        `C:\A\B\c.txt`,
+			b:        `C:\a\b\c.txt`,
+			equal:    false,
+			fold:     true,
+			ancestor: true,
+		},
+		{
+			a:        `C:\a`,
+			b:        `C:\a\b\c.txt`,
+			equal:    false,
+			fold:     false,
+			ancestor: true,
+		},
+		{
+			a:        `C:\a\b`,
+			b:        `C:\a\bc`,
+			equal:    false,
+			fold:     false,
+			ancestor: false,
+		},
+		{
+			a:        `C:\a`,
+			b:        `D:\a`,
+			equal:    false,
+			fold:     false,
+			ancestor: false,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			a, err := normurl.New(c.a, normurl.WithPathStyle(normurl.WindowsStyle))
+			require.NoError(t, err)
+			b, err := normurl.New(c.b, normurl.WithPathStyle(normurl.WindowsStyle))
+			require.NoError(t, err)
+
+			assert.Equal(t, c.equal, a.Equal(b))
+			assert.Equal(t, c.fold, a.EqualFold(b))
+			assert.Equal(t, c.ancestor, a.IsAncestor(b))
+		})
+	}
+}
+
+func TestPathMethodsWindowsStyle(t *testing.T) {
+	l, err := normurl.New("file:///C:/a/b/c.txt", normurl.WithPathStyle(normurl.WindowsStyle))
+	require.NoError(t, err)
+
+	assert.Equal(t, "c.txt", l.Base())
+	assert.Equal(t, ".txt", l.Ext())
+
+	joined, err := l.Dir()
+	require.NoError(t, err)
+	joined, err = joined.JoinPath("x", "y.txt")
+	require.NoError(t, err)
+	path, err := joined.FilePath()
+	require.NoError(t, err)
+	assert.Equal(t, `C:\a\b\x\y.txt`, path)
+
+	trimmed := normurl.MustNew(`C:\a\b\`, normurl.WithPathStyle(normurl.WindowsStyle)).TrimTrailingSlash()
+	path, err = trimmed.FilePath()
+	require.NoError(t, err)
+	assert.Equal(t, `C:\a\b`, path)
+
+	root := normurl.MustNew(`C:\`, normurl.WithPathStyle(normurl.WindowsStyle)).TrimTrailingSlash()
+	path, err = root.FilePath()
+	require.NoError(t, err)
+	assert.Equal(t, `C:\`, path)
+
+	ok, err := l.Matches("file:///C:/a/**/*.txt")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	target := normurl.MustNew(`C:\a\d\e.txt`, normurl.WithPathStyle(normurl.WindowsStyle))
+	rel, err := l.Rel(target)
+	require.NoError(t, err)
+	assert.Equal(t, "../d/e.txt", rel)
+
+	resolved, err := l.Resolve(rel)
+	require.NoError(t, err)
+	assert.True(t, target.Equal(resolved))
+
+	_, err = l.Rel(normurl.MustNew(`D:\a.txt`, normurl.WithPathStyle(normurl.WindowsStyle)))
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package normurl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrSchemeNotAllowed is returned when a locator scheme is not allowed by a policy.
+	ErrSchemeNotAllowed = errors.New("scheme not allowed")
+	// ErrUserinfoNotAllowed is returned when a URL includes a username or password that is not allowed.
+	ErrUserinfoNotAllowed = errors.New("userinfo not allowed")
+	// ErrLoopbackNotAllowed is returned when a URL points at a loopback address that is not allowed by a policy.
+	ErrLoopbackNotAllowed = errors.New("loopback not allowed")
+	// ErrTooLong is returned when a locator exceeds a maximum length.
+	ErrTooLong = errors.New("too long")
+)
+
+// Policy describes the rules enforced by Validate.
+type Policy struct {
+	// AllowedSchemes lists the allowed schemes ("file" for file paths). If
+	// empty, any scheme is allowed.
+	AllowedSchemes []string
+	// AllowUserinfo allows URLs that include a username or password.
+	AllowUserinfo bool
+	// AllowLoopback allows URLs that point at localhost or a loopback address.
+	AllowLoopback bool
+	// MaxLength is the maximum length of the locator string. If zero, there is no limit.
+	MaxLength int
+}
+
+// Validate checks a locator against a policy and returns an error for the
+// first rule that is violated.
+func (l *Locator) Validate(policy Policy) error {
+	if len(policy.AllowedSchemes) > 0 {
+		allowed := false
+		for _, scheme := range policy.AllowedSchemes {
+			if strings.EqualFold(scheme, l.Scheme()) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s", ErrSchemeNotAllowed, l.Scheme())
+		}
+	}
+
+	if !policy.AllowUserinfo {
+		if _, _, ok := l.Userinfo(); ok {
+			return ErrUserinfoNotAllowed
+		}
+	}
+
+	if !policy.AllowLoopback && l.IsLoopback() {
+		return fmt.Errorf("%w: %s", ErrLoopbackNotAllowed, l.Host())
+	}
+
+	if policy.MaxLength > 0 {
+		if n := len(l.String()); n > policy.MaxLength {
+			return fmt.Errorf("%w: length %d exceeds %d", ErrTooLong, n, policy.MaxLength)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package normurl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// trackingParams are the query params removed by StripTrackingParams (in
+// addition to params with a "utm_" prefix).
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"gbraid":  true,
+	"wbraid":  true,
+	"msclkid": true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+}
+
+// StripParams creates a new locator with the named query params removed. The
+// order of the remaining params is unchanged.
+func (l *Locator) StripParams(names ...string) *Locator {
+	remove := make(map[string]bool, len(names))
+	for _, name := range names {
+		remove[name] = true
+	}
+	return l.stripParams(func(name string) bool {
+		return remove[name]
+	})
+}
+
+// StripTrackingParams creates a new locator with common tracking query params
+// removed. This includes params with a "utm_" prefix (e.g. "utm_source") and
+// click identifiers like "fbclid" and "gclid".
+func (l *Locator) StripTrackingParams() *Locator {
+	return l.stripParams(func(name string) bool {
+		return strings.HasPrefix(name, "utm_") || trackingParams[name]
+	})
+}
+
+func (l *Locator) stripParams(remove func(name string) bool) *Locator {
+	clone := l.Clone()
+	if l.kind != KindURL || clone.url.RawQuery == "" {
+		return clone
+	}
+
+	parts := strings.Split(clone.url.RawQuery, "&")
+	kept := parts[:0]
+	for _, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if !remove(name) {
+			kept = append(kept, part)
+		}
+	}
+	clone.url.RawQuery = strings.Join(kept, "&")
+	if clone.url.RawQuery == "" {
+		clone.url.ForceQuery = false
+	}
+	return clone
+}
+
+// DecodeQuery sets the fields of the struct pointed to by v from the query
+// params of a URL. The param name for a field is given by a "query" struct tag
+// (or the field name if there is no tag), and a field with a "-" tag is
+// skipped. String, bool, integer, and float fields are supported, as are
+// slices of these for params with multiple values. Fields for params that are
+// not present are left unchanged, and only the first value is used for a
+// field that is not a slice. An error is returned for a file path.
+func (l *Locator) DecodeQuery(v any) error {
+	if l.kind != KindURL {
+		return fmt.Errorf("cannot decode the query of a %s locator", l.kind)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("expected a non-nil pointer to a struct")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	query := l.url.Query()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("query"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		values, ok := query[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+			for j, value := range values {
+				if err := setQueryValue(slice.Index(j), value); err != nil {
+					return fmt.Errorf("invalid value %q for query param %q: %w", value, name, err)
+				}
+			}
+			fv.Set(slice)
+			continue
+		}
+		if err := setQueryValue(fv, values[0]); err != nil {
+			return fmt.Errorf("invalid value %q for query param %q: %w", values[0], name, err)
+		}
+	}
+	return nil
+}
+
+func setQueryValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
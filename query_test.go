@@ -0,0 +1,190 @@
+package normurl_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tschaub/normurl"
+)
+
+type searchQuery struct {
+	Page    int      `query:"page"`
+	Active  bool     `query:"active"`
+	Search  string   `query:"q"`
+	Score   float64  `query:"score"`
+	Limit   uint8    `query:"limit"`
+	Tags    []string `query:"tag"`
+	Ids     []int    `query:"id"`
+	Ignored string   `query:"-"`
+	Name    string
+	hidden  string
+}
+
+func TestDecodeQuery(t *testing.T) {
+	l, err := normurl.New("https://example.com/search?page=2&active=true&q=a+b&score=0.5&limit=10&tag=x&tag=y&id=1&id=2&Ignored=no&Name=n&hidden=h")
+	require.NoError(t, err)
+
+	query := searchQuery{Search: "unchanged"}
+	require.NoError(t, l.DecodeQuery(&query))
+
+	assert.Equal(t, searchQuery{
+		Page:   2,
+		Active: true,
+		Search: "a b",
+		Score:  0.5,
+		Limit:  10,
+		Tags:   []string{"x", "y"},
+		Ids:    []int{1, 2},
+		Name:   "n",
+	}, query)
+}
+
+func TestDecodeQueryMissing(t *testing.T) {
+	l, err := normurl.New("https://example.com/search?page=3")
+	require.NoError(t, err)
+
+	query := searchQuery{Active: true, Search: "default"}
+	require.NoError(t, l.DecodeQuery(&query))
+	assert.Equal(t, searchQuery{Page: 3, Active: true, Search: "default"}, query)
+}
+
+func TestDecodeQueryErrors(t *testing.T) {
+	cases := []struct {
+		input string
+		value any
+		err   string
+	}{
+		{
+			input: "https://example.com?page=two",
+			value: &searchQuery{},
+			err:   `invalid value "two" for query param "page": strconv.ParseInt: parsing "two": invalid syntax`,
+		},
+		{
+			input: "https://example.com?active=maybe",
+			value: &searchQuery{},
+			err:   `invalid value "maybe" for query param "active": strconv.ParseBool: parsing "maybe": invalid syntax`,
+		},
+		{
+			input: "https://example.com?limit=300",
+			value: &searchQuery{},
+			err:   `invalid value "300" for query param "limit": strconv.ParseUint: parsing "300": value out of range`,
+		},
+		{
+			input: "https://example.com?id=1&id=x",
+			value: &searchQuery{},
+			err:   `invalid value "x" for query param "id": strconv.ParseInt: parsing "x": invalid syntax`,
+		},
+		{
+			input: "https://example.com?page=2",
+			value: searchQuery{},
+			err:   "expected a non-nil pointer to a struct",
+		},
+		{
+			input: "https://example.com?page=2",
+			value: (*searchQuery)(nil),
+			err:   "expected a non-nil pointer to a struct",
+		},
+		{
+			input: "https://example.com?when=now",
+			value: &struct {
+				When struct{} `query:"when"`
+			}{},
+			err: `invalid value "now" for query param "when": unsupported field type struct {}`,
+		},
+		{
+			input: "/path/to/file",
+			value: &searchQuery{},
+			err:   "cannot decode the query of a file locator",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input)
+			require.NoError(t, err)
+
+			assert.EqualError(t, l.DecodeQuery(c.value), c.err)
+		})
+	}
+}
+
+func TestStripParams(t *testing.T) {
+	cases := []struct {
+		input    string
+		names    []string
+		expected string
+	}{
+		{
+			input:    "https://example.com/path?b=2&a=1&c=3#top",
+			names:    []string{"a"},
+			expected: "https://example.com/path?b=2&c=3#top",
+		},
+		{
+			input:    "https://example.com/path?a=1&a=2&b=3",
+			names:    []string{"a", "missing"},
+			expected: "https://example.com/path?b=3",
+		},
+		{
+			input:    "https://example.com/path?a=1",
+			names:    []string{"a"},
+			expected: "https://example.com/path",
+		},
+		{
+			input:    "https://example.com/path?A=1",
+			names:    []string{"a"},
+			expected: "https://example.com/path?A=1",
+		},
+		{
+			input:    "/path/to/file",
+			names:    []string{"a"},
+			expected: "/path/to/file",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			skipUnlessGOOS(t, "!windows")
+
+			l, err := normurl.New(c.input)
+			require.NoError(t, err)
+
+			stripped := l.StripParams(c.names...)
+			assert.Equal(t, c.expected, stripped.String())
+			assert.Equal(t, c.input, l.String())
+		})
+	}
+}
+
+func TestStripTrackingParams(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "https://example.com/article?id=42&utm_source=news&utm_medium=email&fbclid=abc",
+			expected: "https://example.com/article?id=42",
+		},
+		{
+			input:    "https://example.com/article?gclid=abc&page=2&utm_campaign=spring#comments",
+			expected: "https://example.com/article?page=2#comments",
+		},
+		{
+			input:    "https://example.com/article?utm_source=news",
+			expected: "https://example.com/article",
+		},
+		{
+			input:    "https://example.com/article?utmost=1&id=42",
+			expected: "https://example.com/article?utmost=1&id=42",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expected, l.StripTrackingParams().String())
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package normurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[string]func(*url.URL) error{}
+)
+
+// RegisterScheme allows a URL scheme for all locators created by New and
+// validates URLs with that scheme (e.g. to require a host). The validate
+// function may be nil to allow the scheme without further checks. Registering
+// a scheme again replaces its validate function. RegisterScheme panics for the
+// "file" scheme, which is always handled as a file path.
+func RegisterScheme(name string, validate func(*url.URL) error) {
+	name = strings.ToLower(name)
+	if name == "file" {
+		panic("normurl: cannot register the file scheme")
+	}
+
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	schemes[name] = validate
+}
+
+// checkScheme checks that the scheme of a URL is allowed and that the URL is
+// valid for a registered scheme.
+func checkScheme(u *url.URL, o *options) error {
+	schemesMu.RLock()
+	validate, registered := schemes[u.Scheme]
+	schemesMu.RUnlock()
+
+	if !registered && !o.schemes[u.Scheme] {
+		return fmt.Errorf("%w %s", ErrUnsupportedScheme, u.Scheme)
+	}
+	if validate == nil {
+		return nil
+	}
+	if err := validate(u); err != nil {
+		return fmt.Errorf("invalid %s url: %w", u.Scheme, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package normurl_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tschaub/normurl"
+)
+
+func TestRegisterScheme(t *testing.T) {
+	normurl.RegisterScheme("test-s3", func(u *url.URL) error {
+		if u.Host == "" {
+			return errors.New("missing bucket")
+		}
+		return nil
+	})
+	normurl.RegisterScheme("Test-Git+SSH", nil)
+
+	cases := []struct {
+		input    string
+		expected string
+		err      string
+	}{
+		{
+			input:    "test-s3://bucket/path/to/key",
+			expected: "test-s3://bucket/path/to/key",
+		},
+		{
+			input: "test-s3:///path/to/key",
+			err:   "invalid test-s3 url: missing bucket",
+		},
+		{
+			input:    "test-git+ssh://git@example.com/repo.git",
+			expected: "test-git+ssh://git@example.com/repo.git",
+		},
+		{
+			input: "test-other://example.com",
+			err:   "unsupported scheme test-other",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			l, err := normurl.New(c.input)
+			if c.err != "" {
+				assert.Nil(t, l)
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, l.String())
+			assert.Equal(t, normurl.KindURL, l.Kind())
+		})
+	}
+}
+
+func TestRegisterSchemeWithScheme(t *testing.T) {
+	normurl.RegisterScheme("test-gs", func(u *url.URL) error {
+		if u.User != nil {
+			return errors.New("userinfo not supported")
+		}
+		return nil
+	})
+
+	l, err := normurl.New("https://bucket/key")
+	require.NoError(t, err)
+
+	gs, err := l.WithScheme("test-gs")
+	require.NoError(t, err)
+	assert.Equal(t, "test-gs://bucket/key", gs.String())
+
+	l, err = normurl.New("https://user@bucket/key")
+	require.NoError(t, err)
+
+	_, err = l.WithScheme("test-gs")
+	assert.EqualError(t, err, "invalid test-gs url: userinfo not supported")
+}
+
+func TestRegisterSchemeFile(t *testing.T) {
+	assert.Panics(t, func() {
+		normurl.RegisterScheme("file", nil)
+	})
+}